@@ -2,24 +2,47 @@ package main
 
 import (
     "context"
+    "flag"
     "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
     "github.com/korchasa/awesome-toolkit/pkg/commands/adder"
+    "github.com/korchasa/awesome-toolkit/pkg/commands/cache_manager"
+    "github.com/korchasa/awesome-toolkit/pkg/commands/category_suggester"
     "github.com/korchasa/awesome-toolkit/pkg/commands/cleanup"
     "github.com/korchasa/awesome-toolkit/pkg/commands/collector"
+    "github.com/korchasa/awesome-toolkit/pkg/commands/description_rewriter"
+    "github.com/korchasa/awesome-toolkit/pkg/commands/eval"
     "github.com/korchasa/awesome-toolkit/pkg/commands/readme"
+    "github.com/korchasa/awesome-toolkit/pkg/commands/reclassifier"
+    "github.com/korchasa/awesome-toolkit/pkg/commands/revisitor"
+    "github.com/korchasa/awesome-toolkit/pkg/commands/server"
+    "github.com/korchasa/awesome-toolkit/pkg/commands/stats"
     "github.com/korchasa/awesome-toolkit/pkg/config"
     "github.com/korchasa/awesome-toolkit/pkg/github"
-    "github.com/sashabaranov/go-openai"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "github.com/korchasa/awesome-toolkit/pkg/repo_classifier"
+    "github.com/korchasa/awesome-toolkit/pkg/usage"
     log "github.com/sirupsen/logrus"
     "os"
+    "os/signal"
+    "path/filepath"
     "strings"
+    "time"
 )
 
 const (
-    CommandAdd     = "add"
-    CommandCollect = "collect"
-    CommandReadme  = "readme"
-    CommandClean   = "clean"
+    CommandAdd                 = "add"
+    CommandCollect             = "collect"
+    CommandReadme              = "readme"
+    CommandClean               = "clean"
+    CommandServe               = "serve"
+    CommandStats               = "stats"
+    CommandCache               = "cache"
+    CommandEval                = "eval"
+    CommandReclassify          = "reclassify"
+    CommandSuggestCategories   = "suggest-categories"
+    CommandRewriteDescriptions = "rewrite-descriptions"
+    CommandRevisit             = "revisit"
 )
 
 func init() {
@@ -38,47 +61,231 @@ type Command interface {
 }
 
 func main() {
-    openaiToken := ensureEnv("OPENAI_API_KEY")
     githubToken := ensureEnv("AWESOME_GITHUB_TOKEN")
 
-    ctx := context.Background()
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+    defer stop()
 
     commands := []string{
-        CommandAdd, CommandCollect, CommandReadme, CommandClean,
+        CommandAdd, CommandCollect, CommandReadme, CommandClean, CommandServe, CommandStats, CommandCache,
+        CommandEval, CommandReclassify, CommandSuggestCategories, CommandRewriteDescriptions, CommandRevisit,
     }
     if len(os.Args) < 3 {
-        log.Fatalf("Usage: %s <work dir> <%s>", os.Args[0], strings.Join(commands, "|"))
+        log.Fatalf("Usage: %s <work dir> <%s> [flags]", os.Args[0], strings.Join(commands, "|"))
     }
+    flags := flag.NewFlagSet(os.Args[2], flag.ExitOnError)
 
     cfg, err := config.NewFromDir(os.Args[1])
     if err != nil {
         log.Fatalf("failed to load config: %s", err)
     }
 
-    aiClient := openai.NewClient(openaiToken)
+    aiClient := mustBuildLLM(cfg)
     githubClient := github.NewGitHub(githubToken)
+    cacheStore := cache.NewStore(cfg.CachePath())
 
     var cmd Command
     switch os.Args[2] {
     case CommandAdd:
         cmd = adder.MustBuildApp(aiClient, cfg)
     case CommandCollect:
-        cmd = collector.MustBuildApp(githubClient, aiClient, cfg)
+        opts := collector.Options{}
+        flags.BoolVar(&opts.Queue, "queue", false, "classify found repos and queue them for review instead of asking")
+        flags.BoolVar(&opts.Offline, "offline", false, "use only cached search results, readmes and classifications")
+        flags.StringVar(&opts.Classifier, "classifier", "", "classifier engine: llm, local or cascade (default from config)")
+        flags.Float64Var(&cfg.LLM.RunBudget, "budget", cfg.LLM.RunBudget, "stop classification when the run spends this many dollars")
+        flags.BoolVar(&opts.Explain, "explain", false, "log the ignore rules fired on every found repo")
+        parseFlags(flags)
+        opts.Consensus = mustBuildConsensus(cfg, aiClient)
+        cmd = collector.MustBuildApp(githubClient, aiClient, mustBuildEmbedder(cfg), cfg, cacheStore, opts)
     case CommandReadme:
-        cmd = readme.MustBuildApp(cfg)
+        opts := readme.Options{}
+        flags.BoolVar(&opts.Offline, "offline", false, "use only cached translations")
+        flags.BoolVar(&opts.Review, "review", false, "review the new translations, accepted ones are locked")
+        parseFlags(flags)
+        cmd = readme.MustBuildApp(aiClient, cfg, cacheStore, opts)
     case CommandClean:
         cmd = cleanup.MustBuildApp(cfg)
+    case CommandServe:
+        addr := flags.String("addr", "127.0.0.1:8080", "address to listen on")
+        parseFlags(flags)
+        cmd = server.MustBuildApp(githubClient.WithCache(cacheStore, false), cfg, *addr)
+    case CommandStats:
+        parseFlags(flags)
+        cmd = stats.MustBuildApp(cfg, flags.Arg(0))
+    case CommandCache:
+        parseFlags(flags)
+        cmd = cache_manager.MustBuildApp(cacheStore, flags.Arg(0), flags.Arg(1))
+    case CommandEval:
+        opts := eval.Options{}
+        flags.Float64Var(&opts.Split, "split", 0, "share of curated items held out for evaluation, 0 evaluates on all")
+        flags.IntVar(&opts.Limit, "limit", 0, "evaluate at most this number of items")
+        flags.StringVar(&opts.Classifier, "classifier", "", "classifier engine: llm, local or cascade (default from config)")
+        flags.BoolVar(&opts.Adversarial, "adversarial", false, "run the prompt injection regression suite")
+        compare := flags.String("compare", "", "config file with `llm` and `classifier` sections to compare with")
+        parseFlags(flags)
+        setups := []eval.Setup{{Name: "current", Config: cfg, LLM: aiClient, Consensus: mustBuildConsensus(cfg, aiClient)}}
+        if *compare != "" {
+            setups = append(setups, mustBuildVariant(cfg, *compare))
+        }
+        cmd = eval.MustBuildApp(githubClient.WithCache(cacheStore, false), opts, setups...)
+    case CommandReclassify:
+        opts := reclassifier.Options{}
+        flags.StringVar(&opts.Category, "category", "", "reclassify items of the category and its subcategories")
+        flags.DurationVar(&opts.OlderThan, "older-than", 0, "reclassify items added earlier than this long ago, e.g. 720h")
+        flags.Float64Var(&opts.Below, "below", 0, "reclassify items with the AI confidence lower than this")
+        flags.BoolVar(&opts.Ignored, "ignored", false, "reclassify ignored items instead of categorized ones")
+        flags.StringVar(&opts.Scope, "scope", "", "comma-separated categories, review only items suggested to move into them")
+        flags.BoolVar(&opts.Queue, "queue", false, "queue changed items for the web review instead of asking")
+        flags.BoolVar(&opts.Offline, "offline", false, "use only cached readmes and classifications")
+        flags.StringVar(&opts.Classifier, "classifier", "", "classifier engine: llm, local or cascade (default from config)")
+        flags.Float64Var(&cfg.LLM.RunBudget, "budget", cfg.LLM.RunBudget, "stop classification when the run spends this many dollars")
+        parseFlags(flags)
+        opts.Consensus = mustBuildConsensus(cfg, aiClient)
+        cmd = reclassifier.MustBuildApp(githubClient, aiClient, cfg, cacheStore, opts)
+    case CommandSuggestCategories:
+        opts := category_suggester.Options{}
+        flags.Float64Var(&opts.Below, "below", 0.5, "consider items classified with a lower confidence")
+        flags.DurationVar(&opts.Since, "since", 30*24*time.Hour, "consider items ignored by curators this long ago at most")
+        flags.Float64Var(&opts.Similarity, "similarity", 0.75, "minimal similarity of an item to its cluster")
+        flags.IntVar(&opts.MinSize, "min-size", 3, "smallest cluster worth a category")
+        flags.IntVar(&opts.MaxClusters, "max-clusters", 5, "maximal number of clusters to name")
+        parseFlags(flags)
+        cmd = category_suggester.MustBuildApp(aiClient, mustBuildEmbedder(cfg), cfg, opts)
+    case CommandRewriteDescriptions:
+        opts := description_rewriter.Options{}
+        flags.StringVar(&opts.Category, "category", "", "rewrite descriptions of the category and its subcategories")
+        flags.BoolVar(&opts.Violations, "only-violations", false, "rewrite only descriptions breaking the length or emoji rules")
+        flags.IntVar(&opts.Batch, "batch", 10, "number of rewrites approved at once")
+        flags.IntVar(&opts.Limit, "limit", 0, "rewrite at most this number of descriptions")
+        parseFlags(flags)
+        cmd = description_rewriter.MustBuildApp(aiClient, cfg, cacheStore, opts)
+    case CommandRevisit:
+        opts := revisitor.Options{}
+        flags.StringVar(&opts.Reasons, "reason", "", "comma-separated ignore reasons to revisit (default all expiring ones)")
+        flags.DurationVar(&opts.Interval, "interval", 0, "skip items revisited less than this long ago, e.g. 720h")
+        flags.IntVar(&opts.Limit, "limit", 0, "revisit at most this number of items")
+        flags.BoolVar(&opts.Explain, "explain", false, "log the ignore rules fired on every revisited item")
+        flags.StringVar(&opts.Classifier, "classifier", "", "classifier engine: llm, local or cascade (default from config)")
+        flags.Float64Var(&cfg.LLM.RunBudget, "budget", cfg.LLM.RunBudget, "stop classification when the run spends this many dollars")
+        parseFlags(flags)
+        opts.Consensus = mustBuildConsensus(cfg, aiClient)
+        cmd = revisitor.MustBuildApp(githubClient, aiClient, cfg, cacheStore, opts)
     default:
         log.Fatalf("unknown command: %s", os.Args[2])
     }
 
     log.Infof("Starting application")
     err = cmd.Run(ctx)
+    logCacheStats(cacheStore)
+    logUsage(aiClient)
     if err != nil {
         log.Fatalf("failed to run service: %s", err)
     }
 }
 
+func logCacheStats(store *cache.Store) {
+    run := store.RunStats()
+    for _, kind := range cache.SortedKinds(run) {
+        log.Infof("Cache `%s`: %d hits, %d misses", kind, run[kind].Hits, run[kind].Misses)
+    }
+    if err := store.Flush(); err != nil {
+        log.Errorf("failed to save cache stats: %s", err)
+    }
+}
+
+func logUsage(tracker *usage.Tracker) {
+    run := tracker.Run()
+    if len(run) == 0 {
+        return
+    }
+    for _, model := range usage.SortedModels(run) {
+        t := run[model]
+        log.Infof(
+            "LLM `%s`: %d calls, %d prompt and %d completion tokens, $%.4f",
+            model, t.Calls, t.PromptTokens, t.CompletionTokens, t.Cost,
+        )
+    }
+    log.Infof("LLM spending today: $%.4f", tracker.DaySpent())
+}
+
+func parseFlags(flags *flag.FlagSet) {
+    err := flags.Parse(os.Args[3:])
+    if err != nil {
+        log.Fatalf("failed to parse flags: %s", err)
+    }
+}
+
+func mustBuildEmbedder(cfg *config.Config) llm.Embedder {
+    if cfg.Embeddings == nil {
+        return nil
+    }
+    apiKey := ""
+    if cfg.Embeddings.Provider == config.ProviderOpenAI {
+        apiKey = llmAPIKey(cfg.LLM)
+    }
+    embedder, err := llm.NewEmbedder(cfg.LLM, cfg.Embeddings, apiKey)
+    if err != nil {
+        log.Fatalf("failed to create embedder: %s", err)
+    }
+    return embedder
+}
+
+func mustBuildVariant(cfg *config.Config, filename string) eval.Setup {
+    variant, err := cfg.WithVariant(filename)
+    if err != nil {
+        log.Fatalf("failed to load config variant: %s", err)
+    }
+    ai := mustBuildLLM(variant)
+    return eval.Setup{
+        Name:      strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename)),
+        Config:    variant,
+        LLM:       ai,
+        Consensus: mustBuildConsensus(variant, ai),
+    }
+}
+
+// mustBuildConsensus creates the second classifier of the consensus mode, its model spends the budgets of
+// the tracker. Returns nil if the mode is off.
+func mustBuildConsensus(cfg *config.Config, tracker *usage.Tracker) *repo_classifier.ConsensusSetup {
+    if cfg.Classifier.Consensus == "" {
+        return nil
+    }
+    second, err := cfg.WithVariant(cfg.WorkPath(cfg.Classifier.Consensus))
+    if err != nil {
+        log.Fatalf("failed to load consensus variant: %s", err)
+    }
+    // the variant inherits the classifier section when it has none, the second classifier has no consensus
+    classifier := *second.Classifier
+    classifier.Consensus = ""
+    second.Classifier = &classifier
+    ai, err := llm.New(second.LLM, llmAPIKey(second.LLM))
+    if err != nil {
+        log.Fatalf("failed to create consensus llm client: %s", err)
+    }
+    return &repo_classifier.ConsensusSetup{Config: second, LLM: tracker.Track(ai, second.LLM)}
+}
+
+// mustBuildLLM creates the model client that records its usage in the work dir and respects the budgets
+func mustBuildLLM(cfg *config.Config) *usage.Tracker {
+    ai, err := llm.New(cfg.LLM, llmAPIKey(cfg.LLM))
+    if err != nil {
+        log.Fatalf("failed to create llm client: %s", err)
+    }
+    tracker, err := usage.NewTracker(ai, cfg.LLM, cfg.UsagePath())
+    if err != nil {
+        log.Fatalf("failed to load llm usage: %s", err)
+    }
+    return tracker
+}
+
+func llmAPIKey(cfg *config.LLMConfig) string {
+    if cfg.APIKeyEnv == "" {
+        return ""
+    }
+    return ensureEnv(cfg.APIKeyEnv)
+}
+
 func ensureEnv(name string) string {
     value := os.Getenv(name)
     if value == "" {
@@ -0,0 +1,221 @@
+package cache
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "fmt"
+    "gopkg.in/yaml.v3"
+    "os"
+    "path/filepath"
+    "sort"
+    "strings"
+    "sync"
+)
+
+const (
+    KindSearch         = "search"
+    KindReadme         = "readme"
+    KindClassification = "classification"
+    KindAssessment     = "assessment"
+    KindRewrite        = "rewrite"
+    KindTranslation    = "translation"
+
+    statsFilename = "stats.yaml"
+)
+
+// Kinds are all the kinds of entries
+var Kinds = []string{KindSearch, KindReadme, KindClassification, KindAssessment, KindRewrite, KindTranslation}
+
+var ErrMiss = errors.New("cache miss")
+
+// Store is a content-addressed on-disk cache. Values are kept as YAML files in `<dir>/<kind>/<key>.yaml`.
+type Store struct {
+    dir   string
+    stats map[string]*Counter
+    mu    sync.Mutex
+}
+
+type Counter struct {
+    Hits   int `yaml:"hits"`
+    Misses int `yaml:"misses"`
+}
+
+func NewStore(dir string) *Store {
+    return &Store{
+        dir:   dir,
+        stats: map[string]*Counter{},
+    }
+}
+
+// Key builds a cache key from the hash of all the parts.
+func Key(parts ...string) string {
+    h := sha256.New()
+    for _, p := range parts {
+        h.Write([]byte(fmt.Sprintf("%d:%s", len(p), p)))
+    }
+    return hex.EncodeToString(h.Sum(nil))
+}
+
+func (s *Store) Get(kind string, key string, v interface{}) error {
+    bt, err := os.ReadFile(s.path(kind, key))
+    if os.IsNotExist(err) {
+        s.count(kind, false)
+        return ErrMiss
+    }
+    if err != nil {
+        return fmt.Errorf("failed to read cache: %w", err)
+    }
+    err = yaml.Unmarshal(bt, v)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal cache: %w", err)
+    }
+    s.count(kind, true)
+    return nil
+}
+
+func (s *Store) Put(kind string, key string, v interface{}) error {
+    bt, err := yaml.Marshal(v)
+    if err != nil {
+        return fmt.Errorf("failed to marshal cache: %w", err)
+    }
+    err = os.MkdirAll(filepath.Join(s.dir, kind), 0755)
+    if err != nil {
+        return fmt.Errorf("failed to create cache dir: %w", err)
+    }
+    err = os.WriteFile(s.path(kind, key), bt, 0644)
+    if err != nil {
+        return fmt.Errorf("failed to write cache: %w", err)
+    }
+    return nil
+}
+
+// Clear removes all entries of the kind, or the whole cache if the kind is empty. The kind must be a directory
+// name, so nothing outside the cache is removed.
+func (s *Store) Clear(kind string) error {
+    path := s.dir
+    if kind != "" {
+        if strings.ContainsAny(kind, `/\`) || kind == "." || kind == ".." {
+            return fmt.Errorf("invalid cache kind `%s`", kind)
+        }
+        path = filepath.Join(s.dir, kind)
+    }
+    err := os.RemoveAll(path)
+    if err != nil {
+        return fmt.Errorf("failed to clear cache: %w", err)
+    }
+    return nil
+}
+
+// Entries returns the number of entries per kind.
+func (s *Store) Entries() (map[string]int, error) {
+    entries := map[string]int{}
+    dirs, err := os.ReadDir(s.dir)
+    if os.IsNotExist(err) {
+        return entries, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to read cache dir: %w", err)
+    }
+    for _, d := range dirs {
+        if !d.IsDir() {
+            continue
+        }
+        files, err := os.ReadDir(filepath.Join(s.dir, d.Name()))
+        if err != nil {
+            return nil, fmt.Errorf("failed to read cache dir: %w", err)
+        }
+        entries[d.Name()] = len(files)
+    }
+    return entries, nil
+}
+
+// RunStats returns hit/miss counters of the current run.
+func (s *Store) RunStats() map[string]Counter {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    res := map[string]Counter{}
+    for k, c := range s.stats {
+        res[k] = *c
+    }
+    return res
+}
+
+// TotalStats returns hit/miss counters accumulated over all runs.
+func (s *Store) TotalStats() (map[string]Counter, error) {
+    total := map[string]Counter{}
+    bt, err := os.ReadFile(filepath.Join(s.dir, statsFilename))
+    if os.IsNotExist(err) {
+        return total, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to read cache stats: %w", err)
+    }
+    err = yaml.Unmarshal(bt, &total)
+    if err != nil {
+        return nil, fmt.Errorf("failed to unmarshal cache stats: %w", err)
+    }
+    return total, nil
+}
+
+// Flush adds the counters of the current run to the accumulated ones.
+func (s *Store) Flush() error {
+    run := s.RunStats()
+    if len(run) == 0 {
+        return nil
+    }
+    total, err := s.TotalStats()
+    if err != nil {
+        return err
+    }
+    for k, c := range run {
+        t := total[k]
+        t.Hits += c.Hits
+        t.Misses += c.Misses
+        total[k] = t
+    }
+    bt, err := yaml.Marshal(total)
+    if err != nil {
+        return fmt.Errorf("failed to marshal cache stats: %w", err)
+    }
+    err = os.MkdirAll(s.dir, 0755)
+    if err != nil {
+        return fmt.Errorf("failed to create cache dir: %w", err)
+    }
+    err = os.WriteFile(filepath.Join(s.dir, statsFilename), bt, 0644)
+    if err != nil {
+        return fmt.Errorf("failed to write cache stats: %w", err)
+    }
+    s.mu.Lock()
+    s.stats = map[string]*Counter{}
+    s.mu.Unlock()
+    return nil
+}
+
+func (s *Store) count(kind string, hit bool) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    c, ok := s.stats[kind]
+    if !ok {
+        c = &Counter{}
+        s.stats[kind] = c
+    }
+    if hit {
+        c.Hits++
+    } else {
+        c.Misses++
+    }
+}
+
+func (s *Store) path(kind string, key string) string {
+    return filepath.Join(s.dir, kind, key+".yaml")
+}
+
+func SortedKinds[V any](m map[string]V) []string {
+    kinds := make([]string, 0, len(m))
+    for k := range m {
+        kinds = append(kinds, k)
+    }
+    sort.Strings(kinds)
+    return kinds
+}
@@ -0,0 +1,34 @@
+package cache
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+)
+
+func TestClearKeepsFilesOutsideCache(t *testing.T) {
+    workDir := t.TempDir()
+    config := filepath.Join(workDir, "config.yaml")
+    if err := os.WriteFile(config, []byte("query: go"), 0644); err != nil {
+        t.Fatal(err)
+    }
+    s := NewStore(filepath.Join(workDir, ".cache"))
+    if err := s.Put(KindSearch, "key", "value"); err != nil {
+        t.Fatal(err)
+    }
+    for _, kind := range []string{"..", ".", "../..", "search/../..", "/", workDir} {
+        if err := s.Clear(kind); err == nil {
+            t.Errorf("Clear(%q) succeeded", kind)
+        }
+    }
+    if _, err := os.Stat(config); err != nil {
+        t.Errorf("config is removed: %s", err)
+    }
+    if err := s.Clear(KindSearch); err != nil {
+        t.Fatal(err)
+    }
+    var v string
+    if err := s.Get(KindSearch, "key", &v); err != ErrMiss {
+        t.Errorf("Get() = %v after clear, want a miss", err)
+    }
+}
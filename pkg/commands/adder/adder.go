@@ -7,8 +7,8 @@ import (
     "github.com/AlecAivazis/survey/v2/terminal"
     "github.com/korchasa/awesome-toolkit/pkg/config"
     "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
     "github.com/korchasa/awesome-toolkit/pkg/repo_classifier"
-    "github.com/sashabaranov/go-openai"
     log "github.com/sirupsen/logrus"
     "os"
     "time"
@@ -21,7 +21,7 @@ type App struct {
     dataPath     string
 }
 
-func MustBuildApp(ai *openai.Client, cfg *config.Config) *App {
+func MustBuildApp(ai llm.LLM, cfg *config.Config) *App {
     return &App{
         classifier:   repo_classifier.NewRepoClassifier(ai, cfg.Root),
         categoryTree: cfg.Root,
@@ -0,0 +1,89 @@
+package cache_manager
+
+import (
+    "context"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
+    log "github.com/sirupsen/logrus"
+    "io"
+    "os"
+    "strings"
+    "text/tabwriter"
+)
+
+const (
+    ActionStats = "stats"
+    ActionClear = "clear"
+)
+
+type App struct {
+    store  *cache.Store
+    action string
+    kind   string
+    out    io.Writer
+}
+
+func MustBuildApp(store *cache.Store, action string, kind string) *App {
+    if action != ActionStats && action != ActionClear {
+        log.Fatalf("unknown cache action `%s`, available: %s, %s", action, ActionStats, ActionClear)
+    }
+    if kind != "" && !knownKind(kind) {
+        log.Fatalf("unknown cache kind `%s`, available: %s", kind, strings.Join(cache.Kinds, ", "))
+    }
+    return &App{
+        store:  store,
+        action: action,
+        kind:   kind,
+        out:    os.Stdout,
+    }
+}
+
+func knownKind(kind string) bool {
+    for _, k := range cache.Kinds {
+        if k == kind {
+            return true
+        }
+    }
+    return false
+}
+
+func (s *App) Run(_ context.Context) error {
+    if s.action == ActionClear {
+        err := s.store.Clear(s.kind)
+        if err != nil {
+            return err
+        }
+        if s.kind == "" {
+            log.Infof("Cache cleared")
+        } else {
+            log.Infof("Cache `%s` cleared", s.kind)
+        }
+        return nil
+    }
+    entries, err := s.store.Entries()
+    if err != nil {
+        return err
+    }
+    total, err := s.store.TotalStats()
+    if err != nil {
+        return err
+    }
+    kinds := map[string]bool{}
+    for k := range entries {
+        kinds[k] = true
+    }
+    for k := range total {
+        kinds[k] = true
+    }
+    w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "Kind\tEntries\tHits\tMisses\tHit rate")
+    for _, k := range cache.SortedKinds(kinds) {
+        c := total[k]
+        rate := "-"
+        if c.Hits+c.Misses > 0 {
+            rate = fmt.Sprintf("%.1f%%", float64(c.Hits)/float64(c.Hits+c.Misses)*100)
+        }
+        fmt.Fprintf(w, "%s\t%d\t%d\t%d\t%s\n", k, entries[k], c.Hits, c.Misses, rate)
+    }
+    return w.Flush()
+}
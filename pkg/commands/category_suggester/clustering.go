@@ -0,0 +1,68 @@
+package category_suggester
+
+import (
+    "github.com/korchasa/awesome-toolkit/pkg/embeddings"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "sort"
+)
+
+type cluster struct {
+    items    []*list.Item
+    vectors  [][]float32
+    centroid []float32
+}
+
+func (c *cluster) add(item *list.Item, vec []float32) {
+    c.items = append(c.items, item)
+    c.vectors = append(c.vectors, vec)
+    if c.centroid == nil {
+        c.centroid = make([]float32, len(vec))
+    }
+    n := float32(len(c.items))
+    for i := range c.centroid {
+        c.centroid[i] += (vec[i] - c.centroid[i]) / n
+    }
+}
+
+// clusterItems groups the items greedily: each item joins the cluster with the most similar centroid, or starts
+// a new one when no centroid is similar enough. The clusters are ordered by size, largest first.
+func clusterItems(items []*list.Item, vectors [][]float32, similarity float64) []*cluster {
+    var clusters []*cluster
+    for i, item := range items {
+        var best *cluster
+        bestSim := similarity
+        for _, c := range clusters {
+            if sim := embeddings.Cosine(vectors[i], c.centroid); sim >= bestSim {
+                best, bestSim = c, sim
+            }
+        }
+        if best == nil {
+            best = &cluster{}
+            clusters = append(clusters, best)
+        }
+        best.add(item, vectors[i])
+    }
+    sort.SliceStable(clusters, func(i, j int) bool {
+        return len(clusters[i].items) > len(clusters[j].items)
+    })
+    return clusters
+}
+
+// representatives returns up to n items closest to the centroid
+func (c *cluster) representatives(n int) []*list.Item {
+    idx := make([]int, len(c.items))
+    for i := range idx {
+        idx[i] = i
+    }
+    sort.SliceStable(idx, func(a, b int) bool {
+        return embeddings.Cosine(c.vectors[idx[a]], c.centroid) > embeddings.Cosine(c.vectors[idx[b]], c.centroid)
+    })
+    var items []*list.Item
+    for i, j := range idx {
+        if i == n {
+            break
+        }
+        items = append(items, c.items[j])
+    }
+    return items
+}
@@ -66,7 +66,7 @@ func (s *App) removeItemsWithoutCategory(data *list.List) {
     log.Infof("Remove items without category...")
     var newItems []*list.Item
     for _, item := range data.Items {
-        if item.Category == "" && !item.Ignore {
+        if item.Category == "" && !item.Ignore && !item.Pending {
             log.Infof("Removed item without category: %s", item.Link)
             continue
         }
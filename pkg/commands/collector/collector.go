@@ -2,42 +2,122 @@ package collector
 
 import (
     "context"
+    "errors"
     "fmt"
     "github.com/AlecAivazis/survey/v2"
     "github.com/AlecAivazis/survey/v2/terminal"
+    "github.com/korchasa/awesome-toolkit/pkg/assessor"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
     "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/decisions"
+    "github.com/korchasa/awesome-toolkit/pkg/embeddings"
     "github.com/korchasa/awesome-toolkit/pkg/github"
     "github.com/korchasa/awesome-toolkit/pkg/ignorer"
     "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
     "github.com/korchasa/awesome-toolkit/pkg/repo_classifier"
-    "github.com/sashabaranov/go-openai"
+    "github.com/korchasa/awesome-toolkit/pkg/review_printer"
+    "github.com/korchasa/awesome-toolkit/pkg/usage"
     log "github.com/sirupsen/logrus"
     "os"
+    "strings"
     "time"
 )
 
+// similarItemsShown is the number of the most similar curated items shown during the review
+const similarItemsShown = 3
+
 type App struct {
-    github       *github.GitHub
-    classifier   *repo_classifier.RepoClassifier
-    tempData     *list.List
-    ignorer      *ignorer.Ignorer
-    categoryTree *config.CategoryDescription
-    query        string
-    dataPath     string
-    tempDataPath string
+    github        *github.GitHub
+    classifier    repo_classifier.Classifier
+    assessor      *assessor.Assessor
+    suggester     *embeddings.Suggester
+    tempData      *list.List
+    ignorer       *ignorer.Ignorer
+    categoryTree  *config.CategoryDescription
+    query         string
+    dataPath      string
+    tempDataPath  string
+    decisionsPath string
+    queue         bool
+    explain       bool
+}
+
+type Options struct {
+    // Queue makes found repos classified and queued for review instead of asking
+    Queue bool
+    // Offline makes only cached search results, readmes and classifications used
+    Offline bool
+    // Classifier is the classification engine, see config.ClassifierConfig.Engine
+    Classifier string
+    // Consensus is the second classifier of the consensus mode, nil disables the mode
+    Consensus *repo_classifier.ConsensusSetup
+    // Explain logs the ignore rules fired on every found repo
+    Explain bool
 }
 
-func MustBuildApp(gh *github.GitHub, ai *openai.Client, cfg *config.Config) *App {
+func MustBuildApp(
+    gh *github.GitHub,
+    ai llm.LLM,
+    embedder llm.Embedder,
+    cfg *config.Config,
+    store *cache.Store,
+    opts Options,
+) *App {
+    tempData := mustLoadTempData(cfg)
+    gh = gh.WithCache(store, opts.Offline)
+    var suggester *embeddings.Suggester
+    if embedder != nil {
+        var err error
+        suggester, err = embeddings.NewSuggester(
+            embedder, cfg.EmbeddingsPath(), tempData.Items, gh.CachedReadme, cfg.Embeddings.Neighbours,
+        )
+        if err != nil {
+            log.Fatalf("failed to load embeddings: %s", err)
+        }
+    }
+    ign, err := ignorer.NewIgnorer(cfg.Ignore)
+    if err != nil {
+        log.Fatalf("failed to build ignorer: %s", err)
+    }
+    assess, err := assessor.Build(ai, cfg, store, opts.Offline)
+    if err != nil {
+        log.Fatalf("failed to build assessor: %s", err)
+    }
     return &App{
-        github:       gh,
-        classifier:   repo_classifier.NewRepoClassifier(ai, cfg.Root),
-        tempData:     mustLoadTempData(cfg),
-        ignorer:      ignorer.NewIgnorer(),
-        categoryTree: cfg.Root,
-        dataPath:     cfg.DataPath(),
-        tempDataPath: cfg.TempDataPath(),
-        query:        cfg.Query,
+        suggester:     suggester,
+        assessor:      assess,
+        github:        gh,
+        classifier:    mustBuildClassifier(ai, gh, cfg, store, tempData, opts),
+        tempData:      tempData,
+        ignorer:       ign,
+        categoryTree:  cfg.Root,
+        dataPath:      cfg.DataPath(),
+        tempDataPath:  cfg.TempDataPath(),
+        decisionsPath: cfg.DecisionsPath(),
+        query:         cfg.Query,
+        queue:         opts.Queue,
+        explain:       opts.Explain,
+    }
+}
+
+func mustBuildClassifier(
+    ai llm.LLM,
+    gh *github.GitHub,
+    cfg *config.Config,
+    store *cache.Store,
+    data *list.List,
+    opts Options,
+) repo_classifier.Classifier {
+    engine := opts.Classifier
+    if engine == "" {
+        engine = cfg.Classifier.Engine
+    }
+    classifier, err := repo_classifier.Build(engine, ai, cfg, data.Items, gh.CachedReadme, store, opts.Offline, opts.Consensus)
+    if err != nil {
+        log.Fatalf("failed to build classifier: %s", err)
     }
+    return classifier
 }
 
 func mustLoadTempData(cfg *config.Config) *list.List {
@@ -60,6 +140,11 @@ func mustLoadTempData(cfg *config.Config) *list.List {
 }
 
 func (s *App) Run(ctx context.Context) error {
+    if s.suggester != nil {
+        if err := s.suggester.Update(ctx); err != nil {
+            log.Errorf("failed to update embeddings, similar items are limited: %s", err)
+        }
+    }
     items, err := s.findNewRepos(ctx)
     if err != nil {
         return fmt.Errorf("failed to find new repos: %w", err)
@@ -67,6 +152,10 @@ func (s *App) Run(ctx context.Context) error {
     count := len(items)
     for i, item := range items {
         stop, err := s.processFoundRepo(ctx, item, i, count)
+        if errors.Is(err, usage.ErrBudgetExceeded) {
+            log.Warnf("Stop classification, %s. Run again later to continue", err)
+            break
+        }
         if err != nil {
             log.Errorf("failed to process repo `%s`: %s", item.Name, err)
             continue
@@ -79,7 +168,7 @@ func (s *App) Run(ctx context.Context) error {
             return fmt.Errorf("failed to save temp data: %w", err)
         }
     }
-    if s.confirmDataReplacement() {
+    if s.queue || s.confirmDataReplacement() {
         log.Infof("saving data to `%s`", s.dataPath)
         s.tempData.UpdatedAt = time.Now()
         err = s.tempData.Save(s.dataPath)
@@ -102,15 +191,39 @@ func (s *App) processFoundRepo(ctx context.Context, item *list.Item, index int,
     if err != nil {
         return true, fmt.Errorf("failed to get readme for `%s`: %w", item.Name, err)
     }
-    s.ignorer.ResolveIgnores(item, readme)
+    verdicts := s.ignorer.ResolveIgnores(item, readme)
+    if s.explain {
+        ignorer.Explain(item, verdicts)
+    }
     if item.Ignore {
-        log.Infof("Skip `%s` because `%s`", item.Name, item.IgnoreReason)
+        log.Infof("Skip `%s` because `%s`: %s", item.Name, item.IgnoreReason, verdicts[0].Explanation)
         return false, nil
     }
     err = s.classifier.ClassifyRepo(ctx, item, readme)
     if err != nil {
         return true, fmt.Errorf("failed to classify repo `%s`: %w", item.Name, err)
     }
+    if s.assessor != nil {
+        err = s.assessor.Assess(ctx, item, readme)
+        if errors.Is(err, usage.ErrBudgetExceeded) {
+            return true, err
+        }
+        if err != nil {
+            log.Warnf("failed to assess `%s`: %s", item.Name, err)
+        }
+    }
+    if s.suggester != nil {
+        similar, err := s.suggester.Suggest(ctx, item, readme)
+        if err != nil {
+            log.Warnf("failed to find similar items for `%s`: %s", item.Name, err)
+        }
+        item.Similar = mostSimilar(similar)
+    }
+    if s.queue {
+        log.Infof("Queue `%s` for review", item.Name)
+        item.Pending = true
+        return false, nil
+    }
     exit, err := s.askForCategory(item, index, count)
     if err != nil {
         return false, fmt.Errorf("failed to ask for category: %w", err)
@@ -118,9 +231,29 @@ func (s *App) processFoundRepo(ctx context.Context, item *list.Item, index int,
     if exit {
         return true, nil
     }
+    err = decisions.Append(s.decisionsPath, decisions.NewDecision(item, decisions.CurrentReviewer()))
+    if err != nil {
+        log.Errorf("failed to record decision for `%s`: %s", item.Name, err)
+    }
     return false, nil
 }
 
+// mostSimilar returns the neighbours shown to the reviewers, here and in the web UI
+func mostSimilar(neighbours []embeddings.Neighbour) (similar []list.Similar) {
+    for i, n := range neighbours {
+        if i == similarItemsShown {
+            break
+        }
+        similar = append(similar, list.Similar{
+            Name:       n.Item.Name,
+            Link:       n.Item.Link,
+            Category:   n.Item.Category,
+            Similarity: float32(n.Similarity),
+        })
+    }
+    return similar
+}
+
 func (s *App) askForCategory(item *list.Item, index int, count int) (stop bool, err error) {
     fmt.Println("=====================================")
     fmt.Printf("Name:\n    %s\n", item.Name)
@@ -128,22 +261,52 @@ func (s *App) askForCategory(item *list.Item, index int, count int) (stop bool,
     fmt.Printf("Description:\n    %s\n", item.Description)
     fmt.Printf("AIDescription:\n    %s\n", item.AIDescription)
     fmt.Printf("Language:\n    %s\n", item.Language)
+    if item.DetectedLanguage != "" {
+        fmt.Printf("Readme language:\n    %s (%.2f)\n", item.DetectedLanguage, item.DetectedLanguageConfidence)
+    }
     fmt.Printf("Position:\n    %d/%d\n", index, count)
+    if len(item.AISuggestions) > 0 {
+        fmt.Println("Suggestions:")
+        for _, sg := range item.AISuggestions {
+            fmt.Printf("    %s (%d%%) %s\n", sg.Category, int(sg.Confidence*100), sg.Rationale)
+        }
+    }
+    review_printer.PrintFindings(item)
+    if item.KNNCategory != "" {
+        fmt.Printf("Nearest neighbours category:\n    %s (%d%%)\n", item.KNNCategory, int(item.KNNConfidence*100))
+    }
+    if len(item.Similar) > 0 {
+        fmt.Println("Similar items:")
+        for _, n := range item.Similar {
+            fmt.Printf("    %s [%s] %d%% %s\n", n.Name, n.Category, int(n.Similarity*100), n.Link)
+        }
+    }
     fmt.Println("=====================================")
     categories := append(s.categoryTree.TitlesTree(0), "Ignore", "Stop")
+    // the suggestion of an item needing review isn't preselected, so it can't be accepted by a habitual Enter
+    defaultCategory := s.categoryTree.FindTreeForm(item.AICategory)
+    if item.NeedsReview() {
+        defaultCategory = categories[0]
+    }
     var qs = []*survey.Question{
         {
             Name: "Category",
             Prompt: &survey.Select{
                 Message:  "Choose a category:",
                 Options:  categories,
-                Default:  s.categoryTree.FindTreeForm(item.AICategory),
+                Default:  defaultCategory,
                 PageSize: 20,
                 Description: func(value string, index int) string {
-                    if value == s.categoryTree.FindTreeForm(item.AICategory) {
-                        return fmt.Sprintf("%d%%", int(item.AICategoryConfidence*100))
+                    var notes []string
+                    for i, sg := range item.Suggestions() {
+                        if value == s.categoryTree.FindTreeForm(sg.Category) {
+                            notes = append(notes, fmt.Sprintf("#%d %d%%", i+1, int(sg.Confidence*100)))
+                        }
+                    }
+                    if item.KNNCategory != "" && value == s.categoryTree.FindTreeForm(item.KNNCategory) {
+                        notes = append(notes, fmt.Sprintf("neighbours %d%%", int(item.KNNConfidence*100)))
                     }
-                    return ""
+                    return strings.Join(notes, ", ")
                 },
             },
             Validate: survey.Required,
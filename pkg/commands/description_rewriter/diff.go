@@ -0,0 +1,62 @@
+package description_rewriter
+
+import (
+    "fmt"
+    "strings"
+    "unicode/utf8"
+)
+
+// columnWidth is the width of a column of the side-by-side diff
+const columnWidth = 56
+
+// sideBySide renders the texts in two columns, the lines of the changed text are marked with `|`
+func sideBySide(before string, after string) string {
+    left, right := wrap(before, columnWidth), wrap(after, columnWidth)
+    var sb strings.Builder
+    for i := 0; i < len(left) || i < len(right); i++ {
+        l, r := line(left, i), line(right, i)
+        mark := " "
+        if before != after {
+            mark = "|"
+        }
+        row := fmt.Sprintf("    %s%s %s %s", l, strings.Repeat(" ", columnWidth-utf8.RuneCountInString(l)), mark, r)
+        sb.WriteString(strings.TrimRight(row, " ") + "\n")
+    }
+    return sb.String()
+}
+
+func line(lines []string, i int) string {
+    if i < len(lines) {
+        return lines[i]
+    }
+    return ""
+}
+
+// wrap splits the text into lines of at most width runes, breaking on spaces when possible
+func wrap(text string, width int) []string {
+    var lines []string
+    var current []rune
+    for _, word := range strings.Fields(text) {
+        w := []rune(word)
+        for len(w) > width {
+            if len(current) > 0 {
+                lines = append(lines, string(current))
+                current = nil
+            }
+            lines = append(lines, string(w[:width]))
+            w = w[width:]
+        }
+        if len(current) > 0 && len(current)+1+len(w) > width {
+            lines = append(lines, string(current))
+            current = nil
+        }
+        if len(current) > 0 {
+            current = append(current, ' ')
+        }
+        current = append(current, w...)
+    }
+    if len(current) > 0 || len(lines) == 0 {
+        lines = append(lines, string(current))
+    }
+    return lines
+}
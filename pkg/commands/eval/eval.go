@@ -0,0 +1,383 @@
+package eval
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/github"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "github.com/korchasa/awesome-toolkit/pkg/repo_classifier"
+    "github.com/korchasa/awesome-toolkit/pkg/usage"
+    log "github.com/sirupsen/logrus"
+    "io"
+    "os"
+    "sort"
+    "strings"
+    "text/tabwriter"
+)
+
+// topN is the number of suggestions that count for the top-N accuracy
+const topN = 3
+
+const noPrediction = "(none)"
+
+type Options struct {
+    // Split is the share of curated items held out for evaluation, the rest are the few-shot examples and
+    // the local classifier training set. Zero evaluates on all items.
+    Split float64
+    // Limit caps the number of evaluated items, zero means no limit
+    Limit int
+    // Classifier is the classification engine, see config.ClassifierConfig.Engine
+    Classifier string
+    // Adversarial runs the prompt injection regression suite instead of the curated items
+    Adversarial bool
+}
+
+// Setup is a classifier configuration under evaluation
+type Setup struct {
+    Name   string
+    Config *config.Config
+    LLM    llm.LLM
+    // Consensus is the second classifier of the consensus mode, nil disables the mode
+    Consensus *repo_classifier.ConsensusSetup
+}
+
+type App struct {
+    github *github.GitHub
+    setups []Setup
+    opts   Options
+    train  []*list.Item
+    gold   []*list.Item
+    out    io.Writer
+}
+
+type result struct {
+    setup       Setup
+    meters      []*llm.Meter
+    model       string
+    version     string
+    predictions map[string][]list.Suggestion
+    errors      int
+}
+
+func MustBuildApp(gh *github.GitHub, opts Options, setups ...Setup) *App {
+    if opts.Split < 0 || opts.Split >= 1 {
+        log.Fatalf("split must be in [0, 1), got %v", opts.Split)
+    }
+    data, err := list.NewFromFile(setups[0].Config.DataPath())
+    if err != nil {
+        log.Fatalf("failed to load data: %s", err)
+    }
+    train, gold := splitItems(data.Items, opts.Split)
+    if opts.Limit > 0 && len(gold) > opts.Limit {
+        gold = gold[:opts.Limit]
+    }
+    if len(gold) == 0 && !opts.Adversarial {
+        log.Fatalf("no curated items to evaluate on")
+    }
+    return &App{
+        github: gh,
+        setups: setups,
+        opts:   opts,
+        train:  train,
+        gold:   gold,
+        out:    os.Stdout,
+    }
+}
+
+// splitItems returns the curated items for training and for evaluation. An item goes to the held-out part by
+// the hash of its link, so the split is stable between runs.
+func splitItems(items []*list.Item, split float64) (train []*list.Item, gold []*list.Item) {
+    for _, item := range items {
+        if item.Ignore || item.Pending || item.Category == "" {
+            continue
+        }
+        if split == 0 {
+            train = append(train, item)
+            gold = append(gold, item)
+            continue
+        }
+        h := sha256.Sum256([]byte(item.Link))
+        if float64(binary.BigEndian.Uint64(h[:8]))/float64(^uint64(0)) < split {
+            gold = append(gold, item)
+        } else {
+            train = append(train, item)
+        }
+    }
+    return train, gold
+}
+
+func (s *App) Run(ctx context.Context) error {
+    if s.opts.Adversarial {
+        return s.runAdversarial(ctx)
+    }
+    log.Infof("Evaluating on %d items, %d items for training", len(s.gold), len(s.train))
+    if s.opts.Split == 0 {
+        log.Warnf("evaluating on the training items, the local classifier results are optimistic")
+    }
+    var results []*result
+    for _, setup := range s.setups {
+        r, err := s.evaluate(ctx, setup)
+        if err != nil {
+            return fmt.Errorf("failed to evaluate `%s`: %w", setup.Name, err)
+        }
+        results = append(results, r)
+    }
+    s.printSummary(results)
+    s.printPerCategory(results)
+    for _, r := range results {
+        s.printConfusionMatrix(r)
+    }
+    return nil
+}
+
+func (s *App) evaluate(ctx context.Context, setup Setup) (*result, error) {
+    r := &result{
+        setup:       setup,
+        predictions: map[string][]list.Suggestion{},
+    }
+    classifier, err := s.buildClassifier(setup, r)
+    if err != nil {
+        return nil, err
+    }
+    for i, item := range s.gold {
+        if ctx.Err() != nil {
+            return nil, ctx.Err()
+        }
+        // only the inputs of the classifier are copied, so the curated answer can't leak into the prompt
+        candidate := &list.Item{
+            Name:        item.Name,
+            Link:        item.Link,
+            Description: item.Description,
+            Language:    item.Language,
+        }
+        err := classifier.ClassifyRepo(ctx, candidate, s.github.CachedReadme(item))
+        if errors.Is(err, usage.ErrBudgetExceeded) {
+            log.Warnf("Stop evaluation of `%s` at %d/%d, %s", setup.Name, i+1, len(s.gold), err)
+            r.errors += len(s.gold) - i
+            break
+        }
+        if err != nil {
+            log.Warnf("[%s %d/%d] failed to classify `%s`: %s", setup.Name, i+1, len(s.gold), item.Name, err)
+            r.errors++
+            continue
+        }
+        log.Debugf("[%s %d/%d] `%s`: %s, expected %s", setup.Name, i+1, len(s.gold), item.Name, candidate.AICategory, item.Category)
+        r.predictions[item.Link] = candidate.Suggestions()
+        r.model, r.version = candidate.AIModel, candidate.AIPromptVersion
+    }
+    return r, nil
+}
+
+// buildClassifier builds the classifier of the setup with its models metered into the result. Classifications
+// aren't cached, a cached one would skip the meters, so the usage and the cost are of all the evaluated items.
+func (s *App) buildClassifier(setup Setup, r *result) (repo_classifier.Classifier, error) {
+    engine := s.opts.Classifier
+    if engine == "" {
+        engine = setup.Config.Classifier.Engine
+    }
+    meter := llm.NewMeter(setup.LLM)
+    r.meters = append(r.meters, meter)
+    var consensus *repo_classifier.ConsensusSetup
+    if setup.Consensus != nil {
+        second := llm.NewMeter(setup.Consensus.LLM)
+        r.meters = append(r.meters, second)
+        consensus = &repo_classifier.ConsensusSetup{Config: setup.Consensus.Config, LLM: second}
+    }
+    return repo_classifier.Build(engine, meter, setup.Config, s.train, s.github.CachedReadme, nil, false, consensus)
+}
+
+func (r *result) calls() (calls int) {
+    for _, m := range r.meters {
+        calls += m.Calls()
+    }
+    return calls
+}
+
+func (r *result) usage() (u llm.Usage) {
+    for _, m := range r.meters {
+        u.PromptTokens += m.Usage().PromptTokens
+        u.CompletionTokens += m.Usage().CompletionTokens
+        u.TotalTokens += m.Usage().TotalTokens
+    }
+    return u
+}
+
+// cost returns the cost of all models of the setup, or false if some of them have no pricing
+func (r *result) cost() (float64, bool) {
+    pricing := r.setup.Config.LLM.Pricing
+    var cost float64
+    for i, m := range r.meters {
+        if i > 0 {
+            pricing = r.setup.Consensus.Config.LLM.Pricing
+        }
+        price, ok := pricing[m.Model()]
+        if !ok {
+            return 0, false
+        }
+        cost += price.Cost(m.Usage().PromptTokens, m.Usage().CompletionTokens)
+    }
+    return cost, true
+}
+
+func (r *result) predicted(item *list.Item) string {
+    suggestions := r.predictions[item.Link]
+    if len(suggestions) == 0 {
+        return noPrediction
+    }
+    return suggestions[0].Category
+}
+
+func (r *result) inTop(item *list.Item, n int) bool {
+    for i, sg := range r.predictions[item.Link] {
+        if i == n {
+            break
+        }
+        if sg.Category == item.Category {
+            return true
+        }
+    }
+    return false
+}
+
+func (s *App) printSummary(results []*result) {
+    w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+    fmt.Fprintf(w, "\t%s\n", strings.Join(names(results), "\t"))
+    row := func(title string, cell func(r *result) string) {
+        cells := make([]string, len(results))
+        for i, r := range results {
+            cells[i] = cell(r)
+        }
+        fmt.Fprintf(w, "%s\t%s\n", title, strings.Join(cells, "\t"))
+    }
+    row("Model / prompt version", func(r *result) string {
+        return r.model + " / " + r.version
+    })
+    row("Accuracy", func(r *result) string {
+        correct := 0
+        for _, item := range s.gold {
+            if r.predicted(item) == item.Category {
+                correct++
+            }
+        }
+        return percent(correct, len(s.gold))
+    })
+    row(fmt.Sprintf("Top-%d accuracy", topN), func(r *result) string {
+        correct := 0
+        for _, item := range s.gold {
+            if r.inTop(item, topN) {
+                correct++
+            }
+        }
+        return percent(correct, len(s.gold))
+    })
+    row("Errors", func(r *result) string {
+        return fmt.Sprint(r.errors)
+    })
+    row("LLM calls", func(r *result) string {
+        return fmt.Sprint(r.calls())
+    })
+    row("Prompt tokens", func(r *result) string {
+        return fmt.Sprint(r.usage().PromptTokens)
+    })
+    row("Completion tokens", func(r *result) string {
+        return fmt.Sprint(r.usage().CompletionTokens)
+    })
+    row("Cost", func(r *result) string {
+        cost, ok := r.cost()
+        if !ok {
+            return "-"
+        }
+        return fmt.Sprintf("$%.4f", cost)
+    })
+    _ = w.Flush()
+    fmt.Fprintln(s.out)
+}
+
+func (s *App) printPerCategory(results []*result) {
+    support := map[string]int{}
+    for _, item := range s.gold {
+        support[item.Category]++
+    }
+    fmt.Fprintln(s.out, "Per category precision and recall:")
+    w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+    header := []string{"Category", "Items"}
+    for _, name := range names(results) {
+        header = append(header, "Precision "+name, "Recall "+name)
+    }
+    fmt.Fprintln(w, strings.Join(header, "\t"))
+    for _, category := range sortedKeys(support) {
+        cells := []string{category, fmt.Sprint(support[category])}
+        for _, r := range results {
+            predicted, correct := 0, 0
+            for _, item := range s.gold {
+                if r.predicted(item) != category {
+                    continue
+                }
+                predicted++
+                if item.Category == category {
+                    correct++
+                }
+            }
+            cells = append(cells, percent(correct, predicted), percent(correct, support[category]))
+        }
+        fmt.Fprintln(w, strings.Join(cells, "\t"))
+    }
+    _ = w.Flush()
+    fmt.Fprintln(s.out)
+}
+
+// printConfusionMatrix prints expected categories in rows and predicted ones in columns.
+func (s *App) printConfusionMatrix(r *result) {
+    matrix := map[string]map[string]int{}
+    predicted := map[string]bool{}
+    for _, item := range s.gold {
+        if matrix[item.Category] == nil {
+            matrix[item.Category] = map[string]int{}
+        }
+        matrix[item.Category][r.predicted(item)]++
+        predicted[r.predicted(item)] = true
+    }
+    columns := sortedKeys(predicted)
+
+    fmt.Fprintf(s.out, "Confusion matrix of `%s` (rows: expected category, columns: predicted category):\n", r.setup.Name)
+    w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintf(w, "\t%s\t\n", strings.Join(columns, "\t"))
+    for _, row := range sortedKeys(matrix) {
+        cells := make([]string, len(columns))
+        for i, col := range columns {
+            cells[i] = fmt.Sprint(matrix[row][col])
+        }
+        fmt.Fprintf(w, "%s\t%s\t\n", row, strings.Join(cells, "\t"))
+    }
+    _ = w.Flush()
+    fmt.Fprintln(s.out)
+}
+
+func names(results []*result) []string {
+    var n []string
+    for _, r := range results {
+        n = append(n, r.setup.Name)
+    }
+    return n
+}
+
+func percent(part, total int) string {
+    if total == 0 {
+        return "-"
+    }
+    return fmt.Sprintf("%.1f%%", float64(part)/float64(total)*100)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
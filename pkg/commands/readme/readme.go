@@ -2,24 +2,49 @@ package readme
 
 import (
     "context"
+    "errors"
     "fmt"
+    "github.com/AlecAivazis/survey/v2"
+    "github.com/AlecAivazis/survey/v2/terminal"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
     "github.com/korchasa/awesome-toolkit/pkg/config"
     "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
     "github.com/korchasa/awesome-toolkit/pkg/readme_generator"
+    "github.com/korchasa/awesome-toolkit/pkg/translator"
+    "github.com/korchasa/awesome-toolkit/pkg/usage"
     log "github.com/sirupsen/logrus"
     "os"
     "time"
 )
 
+const (
+    optionAccept = "Accept"
+    optionEdit   = "Edit"
+    optionSkip   = "Skip"
+    optionStop   = "Stop"
+)
+
+type Options struct {
+    // Offline uses only cached translations
+    Offline bool
+    // Review asks to accept or edit the translations not reviewed yet, reviewed ones are locked
+    Review bool
+}
+
 type App struct {
-    cfg  *config.Config
-    data *list.List
+    cfg        *config.Config
+    data       *list.List
+    translator *translator.Translator
+    opts       Options
 }
 
-func MustBuildApp(cfg *config.Config) *App {
+func MustBuildApp(ai llm.LLM, cfg *config.Config, store *cache.Store, opts Options) *App {
     return &App{
-        cfg:  cfg,
-        data: mustLoadData(cfg),
+        cfg:        cfg,
+        data:       mustLoadData(cfg),
+        translator: translator.NewTranslator(ai, cfg.Readme.Locale).WithCache(store, opts.Offline),
+        opts:       opts,
     }
 }
 
@@ -34,18 +59,31 @@ func mustLoadData(cfg *config.Config) *list.List {
     return data
 }
 
-func (s *App) Run(_ context.Context) error {
+func (s *App) Run(ctx context.Context) error {
     data, err := list.NewFromFile(s.cfg.DataPath())
     if err != nil {
         log.Fatalf("failed to load old data: %s", err)
     }
-    readme, err := readme_generator.NewReadmeGenerator().Generate(s.cfg, data)
-    if err != nil {
-        log.Fatalf("failed to generate readme: %s", err)
+    if len(s.cfg.Readme.Locales) > 0 {
+        s.translate(ctx, data)
+        if err := data.Save(s.cfg.DataPath()); err != nil {
+            return fmt.Errorf("failed to save data: %w", err)
+        }
+        if s.opts.Review {
+            if err := s.review(data); err != nil {
+                return err
+            }
+        }
     }
-    err = os.WriteFile(s.cfg.ReadmePath(), []byte(readme), 0644)
-    if err != nil {
-        log.Fatalf("failed to write readme: %s", err)
+    for _, locale := range append([]string{s.cfg.Readme.Locale}, s.cfg.Readme.Locales...) {
+        readme, err := readme_generator.NewReadmeGenerator().Generate(s.cfg, data, locale)
+        if err != nil {
+            log.Fatalf("failed to generate readme: %s", err)
+        }
+        err = os.WriteFile(s.cfg.ReadmePathFor(locale), []byte(readme), 0644)
+        if err != nil {
+            log.Fatalf("failed to write readme: %s", err)
+        }
     }
     data.ReadmeGeneratedAt = time.Now()
     err = data.Save(s.cfg.DataPath())
@@ -55,3 +93,109 @@ func (s *App) Run(_ context.Context) error {
     log.Infof("Readme generated at %s", data.ReadmeGeneratedAt)
     return nil
 }
+
+// listed returns the items rendered in the readme
+func listed(data *list.List) (items []*list.Item) {
+    for _, item := range data.Items {
+        if !item.Ignore && item.Category != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
+// translate translates the new and changed descriptions to the readme locales. Items which can't be translated
+// are rendered with the original description.
+func (s *App) translate(ctx context.Context, data *list.List) {
+    translated := 0
+    for _, locale := range s.cfg.Readme.Locales {
+        for _, item := range listed(data) {
+            if ctx.Err() != nil {
+                return
+            }
+            desc := readme_generator.Description(item, s.cfg.Readme.DescriptionPrecedence)
+            if t := item.Translations[locale]; t != nil && t.Source != desc && item.Locked(list.TranslationField(locale)) {
+                log.Warnf("reviewed `%s` translation of `%s` is outdated, unlock it to translate again", locale, item.Name)
+            }
+            changed, err := s.translator.Translate(ctx, item, locale, desc)
+            if errors.Is(err, usage.ErrBudgetExceeded) {
+                log.Warnf("Stop translating, %s", err)
+                return
+            }
+            if err != nil {
+                log.Warnf("failed to translate `%s` to `%s`: %s", item.Name, locale, err)
+                continue
+            }
+            if changed {
+                item.Revision++
+                translated++
+            }
+        }
+    }
+    log.Infof("Translated %d descriptions", translated)
+}
+
+// review asks a curator to accept or edit the translations, accepted ones are locked
+func (s *App) review(data *list.List) error {
+    for _, locale := range s.cfg.Readme.Locales {
+        for _, item := range listed(data) {
+            t := item.Translations[locale]
+            if t == nil || item.Locked(list.TranslationField(locale)) {
+                continue
+            }
+            stop := s.reviewTranslation(item, locale, t)
+            if stop {
+                return nil
+            }
+            if err := data.Save(s.cfg.DataPath()); err != nil {
+                return fmt.Errorf("failed to save data: %w", err)
+            }
+        }
+    }
+    return nil
+}
+
+func (s *App) reviewTranslation(item *list.Item, locale string, t *list.Translation) (stop bool) {
+    fmt.Println("=====================================")
+    fmt.Printf("Name:\n    %s\n", item.Name)
+    fmt.Printf("Description:\n    %s\n", t.Source)
+    fmt.Printf("Translation (%s):\n    %s\n", locale, t.Description)
+    fmt.Println("=====================================")
+    answer := struct{ Action string }{}
+    err := survey.Ask([]*survey.Question{
+        {
+            Name: "Action",
+            Prompt: &survey.Select{
+                Message: "Translation:",
+                Options: []string{optionAccept, optionEdit, optionSkip, optionStop},
+            },
+        },
+    }, &answer)
+    if err != nil {
+        if err == terminal.InterruptErr {
+            os.Exit(0)
+        }
+        log.Warnf("failed to ask for the translation: %s", err)
+        return true
+    }
+    switch answer.Action {
+    case optionStop:
+        return true
+    case optionSkip:
+        return false
+    case optionEdit:
+        edited := t.Description
+        err := survey.AskOne(&survey.Input{Message: "Translation:", Default: t.Description}, &edited)
+        if err != nil {
+            if err == terminal.InterruptErr {
+                os.Exit(0)
+            }
+            log.Warnf("failed to ask for the translation: %s", err)
+            return true
+        }
+        t.Description = edited
+    }
+    item.Locks = append(item.Locks, list.TranslationField(locale))
+    item.Revision++
+    return false
+}
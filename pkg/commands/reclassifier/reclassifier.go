@@ -0,0 +1,307 @@
+package reclassifier
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "github.com/AlecAivazis/survey/v2"
+    "github.com/AlecAivazis/survey/v2/terminal"
+    "github.com/korchasa/awesome-toolkit/pkg/assessor"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/decisions"
+    "github.com/korchasa/awesome-toolkit/pkg/github"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "github.com/korchasa/awesome-toolkit/pkg/repo_classifier"
+    "github.com/korchasa/awesome-toolkit/pkg/review_printer"
+    "github.com/korchasa/awesome-toolkit/pkg/usage"
+    log "github.com/sirupsen/logrus"
+    "os"
+    "strings"
+    "time"
+)
+
+const (
+    optionKeep = "Keep"
+    optionStop = "Stop"
+)
+
+type Options struct {
+    // Category selects the items of the category and its subcategories
+    Category string
+    // OlderThan selects the items added earlier than this long ago
+    OlderThan time.Duration
+    // Below selects the items with the AI confidence lower than this
+    Below float64
+    // Ignored selects the ignored items instead of the categorized ones
+    Ignored bool
+    // Scope is the comma-separated categories, only the items suggested to move into them are reviewed
+    Scope string
+    // Queue marks the changed items pending for the web review instead of asking
+    Queue bool
+    // Offline makes only cached readmes and classifications used
+    Offline bool
+    // Classifier is the classification engine, see config.ClassifierConfig.Engine
+    Classifier string
+    // Consensus is the second classifier of the consensus mode, nil disables the mode
+    Consensus *repo_classifier.ConsensusSetup
+}
+
+type App struct {
+    github        *github.GitHub
+    classifier    repo_classifier.Classifier
+    assessor      *assessor.Assessor
+    data          *list.List
+    categoryTree  *config.CategoryDescription
+    dataPath      string
+    decisionsPath string
+    opts          Options
+    scope         map[string]bool
+}
+
+func MustBuildApp(gh *github.GitHub, ai llm.LLM, cfg *config.Config, store *cache.Store, opts Options) *App {
+    data, err := list.NewFromFile(cfg.DataPath())
+    if err != nil {
+        log.Fatalf("failed to load data: %s", err)
+    }
+    if opts.Category != "" && cfg.Root.FindPath(opts.Category) == nil {
+        log.Fatalf("unknown category `%s`", opts.Category)
+    }
+    var scope map[string]bool
+    if opts.Scope != "" {
+        scope = map[string]bool{}
+        for _, title := range strings.Split(opts.Scope, ",") {
+            title = strings.TrimSpace(title)
+            if cfg.Root.FindPath(title) == nil {
+                log.Fatalf("unknown scope category `%s`", title)
+            }
+            scope[title] = true
+        }
+    }
+    engine := opts.Classifier
+    if engine == "" {
+        engine = cfg.Classifier.Engine
+    }
+    gh = gh.WithCache(store, opts.Offline)
+    classifier, err := repo_classifier.Build(engine, ai, cfg, data.Items, gh.CachedReadme, store, opts.Offline, opts.Consensus)
+    if err != nil {
+        log.Fatalf("failed to build classifier: %s", err)
+    }
+    assess, err := assessor.Build(ai, cfg, store, opts.Offline)
+    if err != nil {
+        log.Fatalf("failed to build assessor: %s", err)
+    }
+    return &App{
+        github:        gh,
+        classifier:    classifier,
+        assessor:      assess,
+        data:          data,
+        categoryTree:  cfg.Root,
+        dataPath:      cfg.DataPath(),
+        decisionsPath: cfg.DecisionsPath(),
+        opts:          opts,
+        scope:         scope,
+    }
+}
+
+func (s *App) Run(ctx context.Context) error {
+    items := s.selectItems()
+    log.Infof("Reclassify %d items", len(items))
+    var changes []*list.Item
+    for i, item := range items {
+        if ctx.Err() != nil {
+            break
+        }
+        candidate, err := s.classify(ctx, item)
+        if errors.Is(err, usage.ErrBudgetExceeded) {
+            log.Warnf("Stop reclassification at %d/%d, %s", i+1, len(items), err)
+            break
+        }
+        if err != nil {
+            log.Errorf("failed to reclassify `%s`: %s", item.Name, err)
+            continue
+        }
+        item.UpdateAIFields(candidate)
+        item.Revision++
+        if s.changed(item) {
+            log.Infof("[%d/%d] `%s`: %s -> %s", i+1, len(items), item.Name, current(item), item.AICategory)
+            changes = append(changes, item)
+        }
+    }
+    if err := s.data.Save(s.dataPath); err != nil {
+        return fmt.Errorf("failed to save data: %w", err)
+    }
+    log.Infof("%d of %d items are suggested to move", len(changes), len(items))
+    if s.opts.Queue {
+        for _, item := range changes {
+            item.Pending = true
+        }
+        return s.data.Save(s.dataPath)
+    }
+    for i, item := range changes {
+        stop := s.review(item, i, len(changes))
+        if stop {
+            break
+        }
+        if err := s.data.Save(s.dataPath); err != nil {
+            return fmt.Errorf("failed to save data: %w", err)
+        }
+    }
+    return nil
+}
+
+func (s *App) selectItems() (items []*list.Item) {
+    for _, item := range s.data.Items {
+        if item.Pending || item.Ignore != s.opts.Ignored {
+            continue
+        }
+        if s.opts.Category != "" && !s.inCategory(item.Category, s.opts.Category) {
+            continue
+        }
+        if s.opts.OlderThan > 0 && time.Since(item.CreatedAt) < s.opts.OlderThan {
+            continue
+        }
+        if s.opts.Below > 0 && float64(item.AICategoryConfidence) >= s.opts.Below {
+            continue
+        }
+        items = append(items, item)
+    }
+    return items
+}
+
+// inCategory checks that the category is the parent one or one of its subcategories
+func (s *App) inCategory(category string, parent string) bool {
+    for _, node := range s.categoryTree.FindPath(category) {
+        if node.Title == parent {
+            return true
+        }
+    }
+    return false
+}
+
+// classify runs the classifier on a copy of the item inputs, so the curated category stays untouched
+func (s *App) classify(ctx context.Context, item *list.Item) (*list.Item, error) {
+    readme := s.github.CachedReadme(item)
+    if readme == "" && !s.opts.Offline {
+        var err error
+        readme, err = s.github.GetReadme(ctx, item)
+        if err != nil {
+            log.Warnf("failed to get readme for `%s`, classifying without it: %s", item.Name, err)
+        }
+    }
+    candidate := &list.Item{
+        Name:        item.Name,
+        Link:        item.Link,
+        Description: item.Description,
+        Language:    item.Language,
+    }
+    err := s.classifier.ClassifyRepo(ctx, candidate, readme)
+    if err != nil {
+        return nil, err
+    }
+    if s.assessor != nil {
+        err = s.assessor.Assess(ctx, candidate, readme)
+        if errors.Is(err, usage.ErrBudgetExceeded) {
+            return nil, err
+        }
+        if err != nil {
+            log.Warnf("failed to assess `%s`: %s", item.Name, err)
+        }
+    }
+    return candidate, nil
+}
+
+// changed checks that the suggested category differs from the current one and is in the scope. Suspicious
+// and disputed items are always reviewed, unless a curator locked the category.
+func (s *App) changed(item *list.Item) bool {
+    if item.Locked(list.FieldCategory) {
+        return false
+    }
+    if item.NeedsReview() {
+        return true
+    }
+    if item.AICategory == "" || (item.AICategory == item.Category && !item.Ignore) {
+        return false
+    }
+    for title := range s.scope {
+        if s.inCategory(item.AICategory, title) {
+            return true
+        }
+    }
+    return s.scope == nil
+}
+
+func current(item *list.Item) string {
+    if item.Ignore {
+        return decisions.IgnoredCategory
+    }
+    return item.Category
+}
+
+func (s *App) review(item *list.Item, index int, count int) (stop bool) {
+    fmt.Println("=====================================")
+    fmt.Printf("Name:\n    %s\n", item.Name)
+    fmt.Printf("URL:\n    %s\n", item.Link)
+    fmt.Printf("Description:\n    %s\n", item.Description)
+    fmt.Printf("AIDescription:\n    %s\n", item.AIDescription)
+    if item.CuratorDescription != "" {
+        fmt.Printf("CuratorDescription:\n    %s\n", item.CuratorDescription)
+    }
+    fmt.Printf("Current category:\n    %s\n", current(item))
+    fmt.Printf("Position:\n    %d/%d\n", index+1, count)
+    review_printer.PrintFindings(item)
+    fmt.Println("Suggestions:")
+    for _, sg := range item.Suggestions() {
+        fmt.Printf("    %s (%d%%) %s\n", sg.Category, int(sg.Confidence*100), sg.Rationale)
+    }
+    fmt.Println("=====================================")
+    options := append([]string{optionKeep}, s.categoryTree.TitlesTree(0)...)
+    options = append(options, optionStop)
+    defaultOption := s.categoryTree.FindTreeForm(item.AICategory)
+    if item.NeedsReview() {
+        defaultOption = optionKeep
+    }
+    var qs = []*survey.Question{
+        {
+            Name: "Category",
+            Prompt: &survey.Select{
+                Message:  "Move to:",
+                Options:  options,
+                Default:  defaultOption,
+                PageSize: 20,
+                Description: func(value string, index int) string {
+                    for i, sg := range item.Suggestions() {
+                        if value == s.categoryTree.FindTreeForm(sg.Category) {
+                            return fmt.Sprintf("#%d %d%%", i+1, int(sg.Confidence*100))
+                        }
+                    }
+                    return ""
+                },
+            },
+            Validate: survey.Required,
+        },
+    }
+    answer := struct{ Category string }{}
+    err := survey.Ask(qs, &answer)
+    if err != nil {
+        if err == terminal.InterruptErr {
+            os.Exit(0)
+        }
+    }
+    switch answer.Category {
+    case optionStop:
+        return true
+    case optionKeep:
+    default:
+        item.Category = s.categoryTree.FindByTree(answer.Category)
+        item.Ignore = false
+        item.IgnoreReason = ""
+        item.Revision++
+    }
+    err = decisions.Append(s.decisionsPath, decisions.NewDecision(item, decisions.CurrentReviewer()))
+    if err != nil {
+        log.Errorf("failed to record decision for `%s`: %s", item.Name, err)
+    }
+    return false
+}
@@ -0,0 +1,237 @@
+package revisitor
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/assessor"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/github"
+    "github.com/korchasa/awesome-toolkit/pkg/ignorer"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "github.com/korchasa/awesome-toolkit/pkg/repo_classifier"
+    "github.com/korchasa/awesome-toolkit/pkg/usage"
+    log "github.com/sirupsen/logrus"
+    "strings"
+    "time"
+)
+
+type Options struct {
+    // Reasons are the comma-separated ignore reasons to revisit, empty means all the expiring ones
+    Reasons string
+    // Interval skips the items revisited less than this long ago
+    Interval time.Duration
+    // Limit caps the number of revisited items, zero means no limit
+    Limit int
+    // Explain logs the ignore rules fired on every revisited item
+    Explain bool
+    // Classifier is the classification engine, see config.ClassifierConfig.Engine
+    Classifier string
+    // Consensus is the second classifier of the consensus mode, nil disables the mode
+    Consensus *repo_classifier.ConsensusSetup
+}
+
+type App struct {
+    github     *github.GitHub
+    classifier repo_classifier.Classifier
+    assessor   *assessor.Assessor
+    ignorer    *ignorer.Ignorer
+    data       *list.List
+    dataPath   string
+    reasons    map[string]bool
+    opts       Options
+}
+
+func MustBuildApp(gh *github.GitHub, ai llm.LLM, cfg *config.Config, store *cache.Store, opts Options) *App {
+    data, err := list.NewFromFile(cfg.DataPath())
+    if err != nil {
+        log.Fatalf("failed to load data: %s", err)
+    }
+    var reasons map[string]bool
+    if opts.Reasons != "" {
+        reasons = map[string]bool{}
+        for _, reason := range strings.Split(opts.Reasons, ",") {
+            reason = strings.TrimSpace(reason)
+            if !ignorer.Expires(reason) {
+                log.Fatalf("ignore reason `%s` doesn't expire, there is nothing to revisit", reason)
+            }
+            reasons[reason] = true
+        }
+    }
+    ign, err := ignorer.NewIgnorer(cfg.Ignore)
+    if err != nil {
+        log.Fatalf("failed to build ignorer: %s", err)
+    }
+    engine := opts.Classifier
+    if engine == "" {
+        engine = cfg.Classifier.Engine
+    }
+    gh = gh.WithCache(store, false)
+    classifier, err := repo_classifier.Build(engine, ai, cfg, data.Items, gh.CachedReadme, store, false, opts.Consensus)
+    if err != nil {
+        log.Fatalf("failed to build classifier: %s", err)
+    }
+    assess, err := assessor.Build(ai, cfg, store, false)
+    if err != nil {
+        log.Fatalf("failed to build assessor: %s", err)
+    }
+    return &App{
+        github:     gh,
+        classifier: classifier,
+        assessor:   assess,
+        ignorer:    ign,
+        data:       data,
+        dataPath:   cfg.DataPath(),
+        reasons:    reasons,
+        opts:       opts,
+    }
+}
+
+func (s *App) Run(ctx context.Context) error {
+    items := s.selectItems()
+    log.Infof("Revisit %d ignored items", len(items))
+    returned := 0
+    for i, item := range items {
+        if ctx.Err() != nil {
+            break
+        }
+        back, err := s.revisit(ctx, item)
+        if back {
+            returned++
+        }
+        if errors.Is(err, usage.ErrBudgetExceeded) {
+            log.Warnf("Stop revisiting at %d/%d, %s. Run again later to continue", i+1, len(items), err)
+            break
+        }
+        if err != nil {
+            log.Errorf("failed to revisit `%s`: %s", item.Name, err)
+            continue
+        }
+        if err := s.data.Save(s.dataPath); err != nil {
+            return fmt.Errorf("failed to save data: %w", err)
+        }
+    }
+    if err := s.data.Save(s.dataPath); err != nil {
+        return fmt.Errorf("failed to save data: %w", err)
+    }
+    log.Infof("%d of %d items are queued for review again", returned, len(items))
+    return nil
+}
+
+// selectItems returns the ignored items which reasons can expire. Items ignored by curators have no reason or
+// a free-form one, they stay ignored.
+func (s *App) selectItems() (items []*list.Item) {
+    for _, item := range s.data.Items {
+        if !item.Ignore || item.Pending || !ignorer.Expires(item.IgnoreReason) {
+            continue
+        }
+        if s.reasons != nil && !s.reasons[item.IgnoreReason] {
+            continue
+        }
+        if s.opts.Interval > 0 && time.Since(item.RevisitedAt) < s.opts.Interval {
+            continue
+        }
+        if s.opts.Limit > 0 && len(items) == s.opts.Limit {
+            break
+        }
+        items = append(items, item)
+    }
+    return items
+}
+
+// revisit refreshes the item from GitHub and checks it by the ignore rules again. Items no rule fires on are
+// classified and queued for review, the others keep being ignored with the reason of the first fired rule.
+func (s *App) revisit(ctx context.Context, item *list.Item) (back bool, err error) {
+    fresh, err := s.github.GetRepo(ctx, item)
+    if err != nil {
+        return false, err
+    }
+    readme, err := s.github.GetReadme(ctx, item)
+    if err != nil {
+        return false, fmt.Errorf("failed to get readme: %w", err)
+    }
+    changes := refresh(item, fresh, ignorer.ReadmeHash(readme))
+    reason := item.IgnoreReason
+    item.Ignore, item.IgnoreReason = false, ""
+    verdicts := s.ignorer.ResolveIgnores(item, readme)
+    item.RevisitedAt = time.Now()
+    item.Revision++
+    if s.opts.Explain {
+        ignorer.Explain(item, verdicts)
+    }
+    if len(changes) == 0 {
+        changes = append(changes, "nothing")
+    }
+    if item.Ignore {
+        log.Infof(
+            "`%s` is still ignored because `%s`: %s, changed %s",
+            item.Name, item.IgnoreReason, verdicts[0].Explanation, strings.Join(changes, ", "),
+        )
+        return false, nil
+    }
+    log.Infof("`%s` no longer matches `%s`, changed %s", item.Name, reason, strings.Join(changes, ", "))
+    item.Pending = true
+    return true, s.classify(ctx, item, readme)
+}
+
+// refresh copies the current metadata to the item and returns what has changed. A description edited by
+// curators stays.
+func refresh(item *list.Item, fresh *list.Item, readmeHash string) (changes []string) {
+    if fresh.Stars != item.Stars {
+        changes = append(changes, fmt.Sprintf("stars %d -> %d", item.Stars, fresh.Stars))
+    }
+    if fresh.Archived != item.Archived {
+        changes = append(changes, fmt.Sprintf("archived %t -> %t", item.Archived, fresh.Archived))
+    }
+    if fresh.License != item.License {
+        changes = append(changes, fmt.Sprintf("license `%s` -> `%s`", item.License, fresh.License))
+    }
+    if !fresh.PushedAt.Equal(item.PushedAt) {
+        changes = append(changes, fmt.Sprintf("pushed at %s", fresh.PushedAt.Format(time.DateOnly)))
+    }
+    if readmeHash != item.ReadmeHash {
+        changes = append(changes, "readme")
+    }
+    if fresh.Description != item.Description && !item.Locked(list.FieldDescription) {
+        changes = append(changes, "description")
+        item.Description = fresh.Description
+    }
+    item.Stars = fresh.Stars
+    item.Archived = fresh.Archived
+    item.Fork = fresh.Fork
+    item.License = fresh.License
+    item.PushedAt = fresh.PushedAt
+    item.Language = fresh.Language
+    return changes
+}
+
+// classify fills the AI suggestions of the items ignored before they were classified, so they can be accepted
+// in the review. Earlier suggestions are kept. Items failed to classify are reviewed without suggestions. A copy
+// of the item inputs is classified, the results are copied back except the fields locked by curators.
+func (s *App) classify(ctx context.Context, item *list.Item, readme string) error {
+    candidate := &list.Item{
+        Name:        item.Name,
+        Link:        item.Link,
+        Description: item.Description,
+        Language:    item.Language,
+    }
+    var err error
+    if item.AICategory == "" {
+        err = s.classifier.ClassifyRepo(ctx, candidate, readme)
+        if err != nil && !errors.Is(err, usage.ErrBudgetExceeded) {
+            log.Warnf("failed to classify `%s`, choose the category in the review: %s", item.Name, err)
+            err = nil
+        }
+    }
+    if err == nil && s.assessor != nil && item.AIAssessment == nil {
+        err = s.assessor.Assess(ctx, candidate, readme)
+        if err != nil && !errors.Is(err, usage.ErrBudgetExceeded) {
+            log.Warnf("failed to assess `%s`: %s", item.Name, err)
+            err = nil
+        }
+    }
+    item.UpdateAIFields(candidate)
+    return err
+}
@@ -0,0 +1,365 @@
+package server
+
+import (
+    "context"
+    _ "embed"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/decisions"
+    "github.com/korchasa/awesome-toolkit/pkg/github"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    log "github.com/sirupsen/logrus"
+    "net/http"
+    "os"
+    "strings"
+    "sync"
+    "time"
+)
+
+//go:embed index.html
+var indexPage []byte
+
+var (
+    errNotFound = errors.New("item not found")
+    errConflict = errors.New("item was changed by someone else")
+)
+
+type App struct {
+    github        *github.GitHub
+    categoryTree  *config.CategoryDescription
+    dataPath      string
+    decisionsPath string
+    addr          string
+    mu            sync.Mutex
+}
+
+func MustBuildApp(gh *github.GitHub, cfg *config.Config, addr string) *App {
+    if _, err := os.Stat(cfg.DataPath()); err != nil {
+        log.Fatalf("failed to load data: %s", err)
+    }
+    return &App{
+        github:        gh,
+        categoryTree:  cfg.Root,
+        dataPath:      cfg.DataPath(),
+        decisionsPath: cfg.DecisionsPath(),
+        addr:          addr,
+    }
+}
+
+func (s *App) Run(ctx context.Context) error {
+    mux := http.NewServeMux()
+    mux.HandleFunc("/", s.handleIndex)
+    mux.HandleFunc("/api/categories", s.handleCategories)
+    mux.HandleFunc("/api/items", s.handleItems)
+    mux.HandleFunc("/api/readme", s.handleReadme)
+    mux.HandleFunc("/api/items/accept", s.handleAction(s.accept))
+    mux.HandleFunc("/api/items/recategorize", s.handleAction(s.recategorize))
+    mux.HandleFunc("/api/items/ignore", s.handleAction(s.ignore))
+
+    srv := &http.Server{
+        Addr:              s.addr,
+        Handler:           mux,
+        ReadHeaderTimeout: 10 * time.Second,
+    }
+    go func() {
+        <-ctx.Done()
+        _ = srv.Shutdown(context.Background())
+    }()
+    log.Infof("Serving triage UI at http://%s", s.addr)
+    err := srv.ListenAndServe()
+    if err != nil && !errors.Is(err, http.ErrServerClosed) {
+        return fmt.Errorf("failed to serve: %w", err)
+    }
+    return nil
+}
+
+type itemView struct {
+    Name                 string           `json:"name"`
+    Link                 string           `json:"link"`
+    Description          string           `json:"description"`
+    Language             string           `json:"language"`
+    AICategory           string           `json:"ai_category"`
+    AICategoryConfidence float32          `json:"ai_category_confidence"`
+    AIDescription        string           `json:"ai_description"`
+    KNNCategory          string           `json:"knn_category"`
+    KNNConfidence        float32          `json:"knn_confidence"`
+    Similar              []similarView    `json:"similar"`
+    Suggestions          []suggestionView `json:"suggestions"`
+    InjectionFlags       []string         `json:"injection_flags"`
+    Opinions             []opinionView    `json:"opinions"`
+    Disagreement         bool             `json:"disagreement"`
+    Assessment           *assessmentView  `json:"assessment"`
+    Revision             int              `json:"revision"`
+}
+
+type opinionView struct {
+    Model      string  `json:"model"`
+    Category   string  `json:"category"`
+    Confidence float32 `json:"confidence"`
+    Rationale  string  `json:"rationale"`
+}
+
+type assessmentView struct {
+    Maturity  string   `json:"maturity"`
+    Kind      string   `json:"kind"`
+    Strengths []string `json:"strengths"`
+    Caveats   []string `json:"caveats"`
+}
+
+type similarView struct {
+    Name       string  `json:"name"`
+    Link       string  `json:"link"`
+    Category   string  `json:"category"`
+    Similarity float32 `json:"similarity"`
+}
+
+type suggestionView struct {
+    Category   string  `json:"category"`
+    Confidence float32 `json:"confidence"`
+    Rationale  string  `json:"rationale"`
+}
+
+func newItemView(item *list.Item) itemView {
+    suggestions := []suggestionView{}
+    for _, sg := range item.AISuggestions {
+        suggestions = append(suggestions, suggestionView{
+            Category:   sg.Category,
+            Confidence: sg.Confidence,
+            Rationale:  sg.Rationale,
+        })
+    }
+    opinions := []opinionView{}
+    for _, o := range item.AIOpinions {
+        opinions = append(opinions, opinionView{
+            Model:      o.Model,
+            Category:   o.Category,
+            Confidence: o.Confidence,
+            Rationale:  o.Rationale,
+        })
+    }
+    similar := []similarView{}
+    for _, n := range item.Similar {
+        similar = append(similar, similarView{
+            Name:       n.Name,
+            Link:       n.Link,
+            Category:   n.Category,
+            Similarity: n.Similarity,
+        })
+    }
+    var assessment *assessmentView
+    if a := item.AIAssessment; a != nil {
+        assessment = &assessmentView{
+            Maturity:  a.Maturity,
+            Kind:      a.Kind,
+            Strengths: append([]string{}, a.Strengths...),
+            Caveats:   append([]string{}, a.Caveats...),
+        }
+    }
+    return itemView{
+        Name:                 item.Name,
+        Link:                 item.Link,
+        Description:          item.Description,
+        Language:             item.Language,
+        AICategory:           item.AICategory,
+        AICategoryConfidence: item.AICategoryConfidence,
+        AIDescription:        item.AIDescription,
+        KNNCategory:          item.KNNCategory,
+        KNNConfidence:        item.KNNConfidence,
+        Similar:              similar,
+        Suggestions:          suggestions,
+        InjectionFlags:       append([]string{}, item.InjectionFlags...),
+        Opinions:             opinions,
+        Disagreement:         item.AIDisagreement,
+        Assessment:           assessment,
+        Revision:             item.Revision,
+    }
+}
+
+type categoryView struct {
+    Title string `json:"title"`
+    Label string `json:"label"`
+}
+
+type actionRequest struct {
+    Link     string `json:"link"`
+    Revision int    `json:"revision"`
+    Category string `json:"category"`
+    Reason   string `json:"reason"`
+    Reviewer string `json:"reviewer"`
+}
+
+func (s *App) handleIndex(w http.ResponseWriter, r *http.Request) {
+    if r.URL.Path != "/" {
+        http.NotFound(w, r)
+        return
+    }
+    w.Header().Set("Content-Type", "text/html; charset=utf-8")
+    _, _ = w.Write(indexPage)
+}
+
+func (s *App) handleCategories(w http.ResponseWriter, _ *http.Request) {
+    var cats []categoryView
+    for _, label := range s.categoryTree.TitlesTree(0) {
+        cats = append(cats, categoryView{
+            Title: s.categoryTree.FindByTree(label),
+            Label: label,
+        })
+    }
+    writeJSON(w, http.StatusOK, cats)
+}
+
+func (s *App) handleItems(w http.ResponseWriter, _ *http.Request) {
+    data, err := s.loadData()
+    if err != nil {
+        writeError(w, http.StatusInternalServerError, err)
+        return
+    }
+    views := []itemView{}
+    for _, item := range data.Pending() {
+        views = append(views, newItemView(item))
+    }
+    writeJSON(w, http.StatusOK, views)
+}
+
+func (s *App) handleReadme(w http.ResponseWriter, r *http.Request) {
+    data, err := s.loadData()
+    if err != nil {
+        writeError(w, http.StatusInternalServerError, err)
+        return
+    }
+    item := data.FindByLink(r.URL.Query().Get("link"))
+    if item == nil {
+        writeError(w, http.StatusNotFound, errNotFound)
+        return
+    }
+    readme, err := s.github.GetReadme(r.Context(), item)
+    if err != nil {
+        writeError(w, http.StatusBadGateway, err)
+        return
+    }
+    w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+    _, _ = w.Write([]byte(readme))
+}
+
+func (s *App) handleAction(apply func(item *list.Item, req actionRequest) error) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method != http.MethodPost {
+            w.WriteHeader(http.StatusMethodNotAllowed)
+            return
+        }
+        var req actionRequest
+        if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+            writeError(w, http.StatusBadRequest, fmt.Errorf("failed to decode request: %w", err))
+            return
+        }
+        item, err := s.update(req, apply)
+        switch {
+        case errors.Is(err, errNotFound):
+            writeError(w, http.StatusNotFound, err)
+        case errors.Is(err, errConflict):
+            writeJSON(w, http.StatusConflict, map[string]interface{}{
+                "error": err.Error(),
+                "item":  newItemView(item),
+            })
+        case err != nil:
+            writeError(w, http.StatusBadRequest, err)
+        default:
+            writeJSON(w, http.StatusOK, newItemView(item))
+        }
+    }
+}
+
+// update reloads the data file, checks that the item wasn't changed since the client has seen it,
+// applies the action and saves the data back. Concurrent reviewers are serialized by the mutex, commands
+// working with the same workspace merge their saves with the file by revisions, see list.List.Save, so
+// decisions made here meanwhile are kept. Reading and writing the file isn't locked across processes, saves
+// at the very same moment can still lose one of them.
+func (s *App) update(req actionRequest, apply func(item *list.Item, req actionRequest) error) (*list.Item, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+
+    data, err := s.loadData()
+    if err != nil {
+        return nil, err
+    }
+    item := data.FindByLink(req.Link)
+    if item == nil {
+        return nil, errNotFound
+    }
+    if item.Revision != req.Revision {
+        return item, errConflict
+    }
+    if err := apply(item, req); err != nil {
+        return nil, err
+    }
+    item.Pending = false
+    item.Revision++
+    if err := data.Save(s.dataPath); err != nil {
+        return nil, fmt.Errorf("failed to save data: %w", err)
+    }
+    log.Infof("Reviewed `%s` by `%s`: category=`%s` ignore=%t", item.Link, req.Reviewer, item.Category, item.Ignore)
+    reviewer := req.Reviewer
+    if reviewer == "" {
+        reviewer = "unknown"
+    }
+    err = decisions.Append(s.decisionsPath, decisions.NewDecision(item, reviewer))
+    if err != nil {
+        log.Errorf("failed to record decision for `%s`: %s", item.Link, err)
+    }
+    return item, nil
+}
+
+// accept lists the item in the AI category, an ignored one queued by `reclassify --ignored` is listed again
+func (s *App) accept(item *list.Item, _ actionRequest) error {
+    if item.AICategory == "" {
+        return fmt.Errorf("item `%s` has no AI category", item.Link)
+    }
+    if item.Suspicious() {
+        return fmt.Errorf("item `%s` looks like a prompt injection, choose the category explicitly", item.Link)
+    }
+    if item.AIDisagreement {
+        return fmt.Errorf("the classifiers disagree on item `%s`, choose the category explicitly", item.Link)
+    }
+    item.Category = item.AICategory
+    item.Ignore, item.IgnoreReason = false, ""
+    return nil
+}
+
+// recategorize lists the item in the chosen category, ignored or not
+func (s *App) recategorize(item *list.Item, req actionRequest) error {
+    category := strings.Trim(req.Category, " ")
+    if s.categoryTree.FindTreeForm(category) == "" {
+        return fmt.Errorf("unknown category `%s`", req.Category)
+    }
+    item.Category = category
+    item.Ignore, item.IgnoreReason = false, ""
+    return nil
+}
+
+func (s *App) ignore(item *list.Item, req actionRequest) error {
+    item.Ignore = true
+    item.IgnoreReason = req.Reason
+    return nil
+}
+
+func (s *App) loadData() (*list.List, error) {
+    data, err := list.NewFromFile(s.dataPath)
+    if err != nil {
+        return nil, fmt.Errorf("failed to load data: %w", err)
+    }
+    return data, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    if err := json.NewEncoder(w).Encode(v); err != nil {
+        log.Errorf("failed to write response: %s", err)
+    }
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+    writeJSON(w, status, map[string]string{"error": err.Error()})
+}
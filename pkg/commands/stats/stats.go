@@ -0,0 +1,169 @@
+package stats
+
+import (
+    "context"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/decisions"
+    log "github.com/sirupsen/logrus"
+    "io"
+    "os"
+    "sort"
+    "strings"
+    "text/tabwriter"
+)
+
+const (
+    ReportClassifier = "classifier"
+    noSuggestion     = "(none)"
+    calibrationBins  = 10
+)
+
+type App struct {
+    log    *decisions.Log
+    report string
+    out    io.Writer
+}
+
+func MustBuildApp(cfg *config.Config, report string) *App {
+    if report != ReportClassifier {
+        log.Fatalf("unknown report `%s`, available: %s", report, ReportClassifier)
+    }
+    l, err := decisions.NewFromFile(cfg.DecisionsPath())
+    if err != nil {
+        log.Fatalf("failed to load decisions: %s", err)
+    }
+    return &App{
+        log:    l,
+        report: report,
+        out:    os.Stdout,
+    }
+}
+
+func (s *App) Run(_ context.Context) error {
+    if len(s.log.Decisions) == 0 {
+        fmt.Fprintln(s.out, "No review decisions recorded yet")
+        return nil
+    }
+    s.printAcceptance()
+    s.printConfusionMatrix()
+    s.printCalibration()
+    return nil
+}
+
+func (s *App) printAcceptance() {
+    type counter struct{ total, accepted int }
+    total := counter{}
+    byVersion := map[string]*counter{}
+    for _, d := range s.log.Decisions {
+        key := d.Model + " / " + d.PromptVersion
+        if byVersion[key] == nil {
+            byVersion[key] = &counter{}
+        }
+        total.total++
+        byVersion[key].total++
+        if d.Accepted() {
+            total.accepted++
+            byVersion[key].accepted++
+        }
+    }
+    fmt.Fprintf(s.out, "Acceptance rate: %s (%d/%d)\n\n", percent(total.accepted, total.total), total.accepted, total.total)
+
+    w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "Model / prompt version\tDecisions\tAccepted")
+    for _, key := range sortedKeys(byVersion) {
+        c := byVersion[key]
+        fmt.Fprintf(w, "%s\t%d\t%s\n", key, c.total, percent(c.accepted, c.total))
+    }
+    _ = w.Flush()
+    fmt.Fprintln(s.out)
+}
+
+// printConfusionMatrix prints AI suggestions in rows and curator choices in columns.
+func (s *App) printConfusionMatrix() {
+    matrix := map[string]map[string]int{}
+    chosen := map[string]bool{}
+    for _, d := range s.log.Decisions {
+        suggested := d.AICategory
+        if suggested == "" {
+            suggested = noSuggestion
+        }
+        if matrix[suggested] == nil {
+            matrix[suggested] = map[string]int{}
+        }
+        matrix[suggested][d.Chosen()]++
+        chosen[d.Chosen()] = true
+    }
+    columns := sortedKeys(chosen)
+
+    fmt.Fprintln(s.out, "Confusion matrix (rows: AI category, columns: chosen category):")
+    w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', tabwriter.AlignRight)
+    fmt.Fprintf(w, "\t%s\t\n", strings.Join(columns, "\t"))
+    for _, row := range sortedKeys(matrix) {
+        cells := make([]string, len(columns))
+        for i, col := range columns {
+            cells[i] = fmt.Sprint(matrix[row][col])
+        }
+        fmt.Fprintf(w, "%s\t%s\t\n", row, strings.Join(cells, "\t"))
+    }
+    _ = w.Flush()
+    fmt.Fprintln(s.out)
+}
+
+// printCalibration compares the stated confidence with the observed acceptance in equal-width bins.
+func (s *App) printCalibration() {
+    type bin struct {
+        count      int
+        accepted   int
+        confidence float64
+    }
+    bins := make([]bin, calibrationBins)
+    for _, d := range s.log.Decisions {
+        if d.AICategory == "" {
+            continue
+        }
+        i := int(d.AIConfidence * calibrationBins)
+        if i >= calibrationBins {
+            i = calibrationBins - 1
+        }
+        if i < 0 {
+            i = 0
+        }
+        bins[i].count++
+        bins[i].confidence += float64(d.AIConfidence)
+        if d.Accepted() {
+            bins[i].accepted++
+        }
+    }
+
+    fmt.Fprintln(s.out, "Confidence calibration:")
+    w := tabwriter.NewWriter(s.out, 0, 0, 2, ' ', 0)
+    fmt.Fprintln(w, "Confidence\tDecisions\tMean confidence\tAccepted")
+    for i, b := range bins {
+        if b.count == 0 {
+            continue
+        }
+        fmt.Fprintf(
+            w, "%d-%d%%\t%d\t%.0f%%\t%s\n",
+            i*100/calibrationBins, (i+1)*100/calibrationBins, b.count,
+            b.confidence/float64(b.count)*100, percent(b.accepted, b.count),
+        )
+    }
+    _ = w.Flush()
+}
+
+func percent(part, total int) string {
+    if total == 0 {
+        return "-"
+    }
+    return fmt.Sprintf("%.1f%%", float64(part)/float64(total)*100)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
@@ -1,10 +1,13 @@
 package config
 
 import (
+    "errors"
     "fmt"
     "gopkg.in/yaml.v3"
     "os"
+    "path/filepath"
     "strings"
+    "time"
 )
 
 const (
@@ -13,12 +16,292 @@ const (
     TempDataFilename       = ".data.tmp.yaml"
     ReadmeTemplateFilename = ".readme.tmpl"
     ReadmeFilename         = "README.md"
+    DecisionsFilename      = ".decisions.yaml"
+    CacheDirname           = ".cache"
+    EmbeddingsFilename     = ".embeddings.yaml"
+    UsageFilename          = ".usage.yaml"
+    PromptTemplateFilename = ".classify.tmpl"
+)
+
+const (
+    ProviderOpenAI = "openai"
+    ProviderHash   = "hash"
+
+    defaultModel     = "gpt-3.5-turbo"
+    defaultAPIKeyEnv = "OPENAI_API_KEY"
+    defaultTimeout   = 2 * time.Minute
 )
 
 type Config struct {
-    Query   string
-    Root    *CategoryDescription
-    workDir string
+    Query      string
+    Root       *CategoryDescription
+    LLM        *LLMConfig        `yaml:"llm,omitempty"`
+    Classifier *ClassifierConfig `yaml:"classifier,omitempty"`
+    Embeddings *EmbeddingsConfig `yaml:"embeddings,omitempty"`
+    Assessment *AssessmentConfig `yaml:"assessment,omitempty"`
+    Style      *StyleConfig      `yaml:"style,omitempty"`
+    Readme     *ReadmeConfig     `yaml:"readme,omitempty"`
+    Ignore     *IgnoreConfig     `yaml:"ignore,omitempty"`
+    workDir    string
+}
+
+// LLMConfig describes the chat model provider. Any OpenAI-compatible server can be used by setting BaseURL.
+type LLMConfig struct {
+    Provider string `yaml:"provider"`
+    BaseURL  string `yaml:"base_url,omitempty"`
+    Model    string `yaml:"model"`
+    // Temperature zero means the provider default
+    Temperature float32       `yaml:"temperature,omitempty"`
+    Timeout     time.Duration `yaml:"timeout,omitempty"`
+    // APIKeyEnv is the name of the environment variable with the API key. Local servers usually don't need it.
+    APIKeyEnv string `yaml:"api_key_env,omitempty"`
+    // JSONMode enables `response_format: json_object` requests, disable it for servers that don't support it
+    JSONMode *bool `yaml:"json_mode,omitempty"`
+    // Pricing is the price of the models by name, used to estimate the spending
+    Pricing map[string]ModelPrice `yaml:"pricing,omitempty"`
+    // RunBudget stops classification when a single run spends this much, zero means no limit
+    RunBudget float64 `yaml:"run_budget,omitempty"`
+    // DailyBudget stops classification when all runs of the day spend this much, zero means no limit
+    DailyBudget float64 `yaml:"daily_budget,omitempty"`
+}
+
+// ModelPrice is the price in dollars per million tokens
+type ModelPrice struct {
+    Prompt     float64 `yaml:"prompt"`
+    Completion float64 `yaml:"completion"`
+}
+
+// Cost returns the price of the tokens
+func (p ModelPrice) Cost(promptTokens int, completionTokens int) float64 {
+    return (float64(promptTokens)*p.Prompt + float64(completionTokens)*p.Completion) / 1e6
+}
+
+const (
+    FewShotSimilarity = "similarity"
+    FewShotDiversity  = "diversity"
+
+    ModeFlat         = "flat"
+    ModeHierarchical = "hierarchical"
+
+    EngineLLM     = "llm"
+    EngineLocal   = "local"
+    EngineCascade = "cascade"
+
+    defaultEscalationThreshold = 0.8
+    defaultSuggestions         = 3
+)
+
+type ClassifierConfig struct {
+    // Mode is `flat` (choose among all categories at once) or `hierarchical` (choose top-down, level by level)
+    Mode string `yaml:"mode,omitempty"`
+    // Engine is the classifier used by default: `llm`, `local` (trained on the data file) or `cascade`
+    // (local first, the LLM when the local one is not confident)
+    Engine string `yaml:"engine,omitempty"`
+    // EscalationThreshold is the local classifier confidence below which the cascade asks the LLM
+    EscalationThreshold float32 `yaml:"escalation_threshold,omitempty"`
+    // FewShotExamples is the total number of curated items shown to the model as examples, zero disables them
+    FewShotExamples int `yaml:"few_shot_examples,omitempty"`
+    // FewShotStrategy is how examples are picked: the most similar to the candidate or the most diverse per category
+    FewShotStrategy string `yaml:"few_shot_strategy,omitempty"`
+    // Suggestions is the number of ranked categories with rationales the classifier proposes for review
+    Suggestions int `yaml:"suggestions,omitempty"`
+    // Template is the prompt template file in the work dir, `.classify.tmpl` by default
+    Template string `yaml:"template,omitempty"`
+    // Consensus is the variant file in the work dir with the `llm` and/or `classifier` sections of the second
+    // classifier. Items are classified by both, disagreements need a human review.
+    Consensus string `yaml:"consensus,omitempty"`
+}
+
+const (
+    defaultEmbeddingsModel = "text-embedding-ada-002"
+    defaultNeighbours      = 5
+)
+
+// EmbeddingsConfig enables embeddings of the items, used for nearest neighbour suggestions.
+// The `openai` provider uses the connection settings of the LLM, the `hash` one is a local lexical stand-in.
+type EmbeddingsConfig struct {
+    Provider   string `yaml:"provider"`
+    Model      string `yaml:"model,omitempty"`
+    Dimensions int    `yaml:"dimensions,omitempty"`
+    // Neighbours is the number of nearest curated items voting for the category
+    Neighbours int `yaml:"neighbours,omitempty"`
+}
+
+// AssessmentConfig enables the AI assessment of the found repositories: maturity, kind, strengths and caveats
+type AssessmentConfig struct {
+    // Badges renders the kind and maturity badges of the items in the generated readme
+    Badges bool `yaml:"badges,omitempty"`
+}
+
+// Description fields of the items, named as in the data file
+const (
+    DescriptionCurator = "curator_description"
+    DescriptionAI      = "ai_description"
+    DescriptionRepo    = "description"
+)
+
+var defaultDescriptionPrecedence = []string{DescriptionCurator, DescriptionAI, DescriptionRepo}
+
+const defaultLocale = "en"
+
+// ReadmeConfig describes the generated readme
+type ReadmeConfig struct {
+    // DescriptionPrecedence is the order of the description fields, the first non-empty one is rendered
+    DescriptionPrecedence []string `yaml:"description_precedence,omitempty"`
+    // Locale is the language of the data file and of the main readme
+    Locale string `yaml:"locale,omitempty"`
+    // Locales are the languages the readme is translated to, each one is rendered to `README.<locale>.md`
+    Locales []string `yaml:"locales,omitempty"`
+}
+
+func (c *ReadmeConfig) setDefaults() {
+    if len(c.DescriptionPrecedence) == 0 {
+        c.DescriptionPrecedence = defaultDescriptionPrecedence
+    }
+    if c.Locale == "" {
+        c.Locale = defaultLocale
+    }
+}
+
+func (c *ReadmeConfig) validate() error {
+    for _, field := range c.DescriptionPrecedence {
+        if field != DescriptionCurator && field != DescriptionAI && field != DescriptionRepo {
+            return fmt.Errorf("unknown description field `%s` in `readme.description_precedence`", field)
+        }
+    }
+    seen := map[string]bool{c.Locale: true}
+    for _, locale := range c.Locales {
+        if locale == "" || strings.ContainsAny(locale, "/. ") {
+            return fmt.Errorf("invalid locale `%s` in `readme.locales`", locale)
+        }
+        if seen[locale] {
+            return fmt.Errorf("duplicate locale `%s` in `readme.locales`", locale)
+        }
+        seen[locale] = true
+    }
+    return nil
+}
+
+const defaultMinLanguageConfidence = 0.5
+
+var defaultLanguages = []string{"en"}
+
+const (
+    LicenseAny = "any"
+    LicenseOSI = "osi"
+)
+
+// IgnoreConfig describes the rules ignoring found repositories. Zero values disable the rules, except the
+// language one.
+type IgnoreConfig struct {
+    // Languages are the allowed natural languages of the readmes, ISO 639-1 codes
+    Languages []string `yaml:"languages,omitempty"`
+    // MinLanguageConfidence is the confidence of the detected language below which the repo isn't ignored
+    MinLanguageConfidence float64 `yaml:"min_language_confidence,omitempty"`
+    // MinStars ignores repos with fewer stars
+    MinStars int `yaml:"min_stars,omitempty"`
+    // Archived and Forks ignore archived repos and forks
+    Archived bool `yaml:"archived,omitempty"`
+    Forks    bool `yaml:"forks,omitempty"`
+    // License is `any` to ignore repos without a license, or `osi` to ignore also the ones with a license not
+    // approved by the Open Source Initiative
+    License string `yaml:"license,omitempty"`
+    // MaxInactivityMonths ignores repos last pushed earlier than this many months ago
+    MaxInactivityMonths int `yaml:"max_inactivity_months,omitempty"`
+    // EmptyReadme ignores repos without a readme, with a readme without prose or generated from a template
+    EmptyReadme bool `yaml:"empty_readme,omitempty"`
+    // Blocklist are `owner/repo` globs, e.g. `spammer/*` or `*/homework-*`
+    Blocklist []string `yaml:"blocklist,omitempty"`
+    // Patterns are regular expressions matched against the names and descriptions
+    Patterns []string `yaml:"patterns,omitempty"`
+    // AwesomeLists ignores repos that are awesome lists themselves
+    AwesomeLists bool `yaml:"awesome_lists,omitempty"`
+}
+
+func (c *IgnoreConfig) setDefaults() {
+    if len(c.Languages) == 0 {
+        c.Languages = defaultLanguages
+    }
+    if c.MinLanguageConfidence == 0 {
+        c.MinLanguageConfidence = defaultMinLanguageConfidence
+    }
+}
+
+const defaultMaxDescriptionLength = 120
+
+var defaultStyleRules = []string{
+    "start with a verb in the third person, e.g. `Parses ...` or `Generates ...`",
+    "use the present tense",
+    "no marketing language, superlatives or exclamation marks",
+    "don't repeat the repository name",
+}
+
+// StyleConfig is the style guide of the item descriptions, applied by `rewrite-descriptions`
+type StyleConfig struct {
+    // MaxLength is the longest description in characters
+    MaxLength int `yaml:"max_length,omitempty"`
+    // Emojis allows emojis in descriptions
+    Emojis bool `yaml:"emojis,omitempty"`
+    // Rules are the free-form rules of the guide
+    Rules []string `yaml:"rules,omitempty"`
+}
+
+func (c *StyleConfig) setDefaults() {
+    if c.MaxLength == 0 {
+        c.MaxLength = defaultMaxDescriptionLength
+    }
+    if len(c.Rules) == 0 {
+        c.Rules = defaultStyleRules
+    }
+}
+
+func (c *EmbeddingsConfig) setDefaults() {
+    if c.Provider == "" {
+        c.Provider = ProviderOpenAI
+    }
+    if c.Model == "" {
+        c.Model = defaultEmbeddingsModel
+    }
+    if c.Neighbours == 0 {
+        c.Neighbours = defaultNeighbours
+    }
+}
+
+func (c *ClassifierConfig) setDefaults() {
+    if c.FewShotStrategy == "" {
+        c.FewShotStrategy = FewShotSimilarity
+    }
+    if c.Engine == "" {
+        c.Engine = EngineLLM
+    }
+    if c.Mode == "" {
+        c.Mode = ModeFlat
+    }
+    if c.EscalationThreshold == 0 {
+        c.EscalationThreshold = defaultEscalationThreshold
+    }
+    if c.Suggestions == 0 {
+        c.Suggestions = defaultSuggestions
+    }
+}
+
+func (c *LLMConfig) setDefaults() {
+    if c.Provider == "" {
+        c.Provider = ProviderOpenAI
+    }
+    if c.Model == "" {
+        c.Model = defaultModel
+    }
+    if c.Timeout == 0 {
+        c.Timeout = defaultTimeout
+    }
+    if c.APIKeyEnv == "" && c.BaseURL == "" {
+        c.APIKeyEnv = defaultAPIKeyEnv
+    }
+    if c.JSONMode == nil {
+        enabled := true
+        c.JSONMode = &enabled
+    }
 }
 
 func NewFromDir(dir string) (*Config, error) {
@@ -31,10 +314,60 @@ func NewFromDir(dir string) (*Config, error) {
     if err != nil {
         return nil, fmt.Errorf("failed to unmarshal config: %w", err)
     }
+    if cfg.LLM == nil {
+        cfg.LLM = &LLMConfig{}
+    }
+    cfg.LLM.setDefaults()
+    if cfg.Classifier == nil {
+        cfg.Classifier = &ClassifierConfig{}
+    }
+    cfg.Classifier.setDefaults()
+    if cfg.Embeddings != nil {
+        cfg.Embeddings.setDefaults()
+    }
+    if cfg.Style == nil {
+        cfg.Style = &StyleConfig{}
+    }
+    cfg.Style.setDefaults()
+    if cfg.Readme == nil {
+        cfg.Readme = &ReadmeConfig{}
+    }
+    cfg.Readme.setDefaults()
+    if err := cfg.Readme.validate(); err != nil {
+        return nil, err
+    }
+    if cfg.Ignore == nil {
+        cfg.Ignore = &IgnoreConfig{}
+    }
+    cfg.Ignore.setDefaults()
     cfg.workDir = dir
     return &cfg, nil
 }
 
+// WithVariant returns a copy of the config with the `llm` and `classifier` sections of the file applied,
+// used to compare classifier configurations. Sections missing in the file are kept as is.
+func (c *Config) WithVariant(filename string) (*Config, error) {
+    bt, err := os.ReadFile(filename)
+    if err != nil {
+        return nil, fmt.Errorf("failed to load variant: %w", err)
+    }
+    variant := Config{}
+    err = yaml.Unmarshal(bt, &variant)
+    if err != nil {
+        return nil, fmt.Errorf("failed to unmarshal variant: %w", err)
+    }
+    cfg := *c
+    if variant.LLM != nil {
+        variant.LLM.setDefaults()
+        cfg.LLM = variant.LLM
+    }
+    if variant.Classifier != nil {
+        variant.Classifier.setDefaults()
+        cfg.Classifier = variant.Classifier
+    }
+    return &cfg, nil
+}
+
 func (c *Config) Save() error {
     bt, err := yaml.Marshal(c)
     if err != nil {
@@ -47,6 +380,89 @@ func (c *Config) Save() error {
     return nil
 }
 
+// InsertCategory adds the category under the parent one, or to the top level if the parent is empty. The config
+// file is edited in place, so its comments and the order of keys are kept.
+func (c *Config) InsertCategory(parent string, cat *CategoryDescription) error {
+    target := c.Root
+    if parent != "" {
+        path := c.Root.FindPath(parent)
+        if path == nil {
+            return fmt.Errorf("unknown category `%s`", parent)
+        }
+        target = path[len(path)-1]
+    }
+    filename := c.workDir + "/" + ConfigFilename
+    bt, err := os.ReadFile(filename)
+    if err != nil {
+        return fmt.Errorf("failed to load config: %w", err)
+    }
+    var doc yaml.Node
+    err = yaml.Unmarshal(bt, &doc)
+    if err != nil {
+        return fmt.Errorf("failed to unmarshal config: %w", err)
+    }
+    node := mappingValue(doc.Content[0], "root")
+    if node == nil {
+        return errors.New("config has no `root` section")
+    }
+    if parent != "" {
+        node = findCategoryNode(node, parent)
+        if node == nil {
+            return fmt.Errorf("category `%s` not found in the config file", parent)
+        }
+    }
+    categories := mappingValue(node, "categories")
+    if categories == nil {
+        categories = &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+        node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: "categories"}, categories)
+    }
+    categories.Content = append(categories.Content, &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{
+        {Kind: yaml.ScalarNode, Value: "title"},
+        {Kind: yaml.ScalarNode, Value: cat.Title},
+        {Kind: yaml.ScalarNode, Value: "prompt"},
+        {Kind: yaml.ScalarNode, Value: cat.Prompt},
+    }})
+    bt, err = yaml.Marshal(&doc)
+    if err != nil {
+        return fmt.Errorf("failed to marshal config: %w", err)
+    }
+    err = os.WriteFile(filename, bt, 0644)
+    if err != nil {
+        return fmt.Errorf("failed to save config: %w", err)
+    }
+    target.Categories = append(target.Categories, cat)
+    return nil
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+    if node.Kind != yaml.MappingNode {
+        return nil
+    }
+    for i := 0; i+1 < len(node.Content); i += 2 {
+        if node.Content[i].Value == key {
+            return node.Content[i+1]
+        }
+    }
+    return nil
+}
+
+// findCategoryNode finds the mapping of the category with the title in the subtree of the node
+func findCategoryNode(node *yaml.Node, title string) *yaml.Node {
+    categories := mappingValue(node, "categories")
+    if categories == nil {
+        return nil
+    }
+    for _, child := range categories.Content {
+        if t := mappingValue(child, "title"); t != nil && strings.TrimSpace(t.Value) == strings.TrimSpace(title) {
+            return child
+        }
+        if found := findCategoryNode(child, title); found != nil {
+            return found
+        }
+    }
+    return nil
+}
+
 func (c *Config) DataPath() string {
     return c.workDir + "/" + DataFilename
 }
@@ -63,12 +479,83 @@ func (c *Config) ReadmeTemplatePath() string {
     return c.workDir + "/" + ReadmeTemplateFilename
 }
 
+// ReadmePathFor returns the readme of the locale: `README.md` for the main one, `README.<locale>.md` for translations
+func (c *Config) ReadmePathFor(locale string) string {
+    if locale == c.Readme.Locale {
+        return c.ReadmePath()
+    }
+    return c.workDir + "/" + LocalReadmeFilename(locale)
+}
+
+// ReadmeTemplatePathFor returns the readme template of the locale, `.readme.<locale>.tmpl`, or the main one if
+// the locale has no own template
+func (c *Config) ReadmeTemplatePathFor(locale string) string {
+    if locale != c.Readme.Locale {
+        path := c.workDir + "/" + strings.TrimSuffix(ReadmeTemplateFilename, ".tmpl") + "." + locale + ".tmpl"
+        if _, err := os.Stat(path); err == nil {
+            return path
+        }
+    }
+    return c.ReadmeTemplatePath()
+}
+
+// LocalReadmeFilename returns the name of the translated readme
+func LocalReadmeFilename(locale string) string {
+    return strings.TrimSuffix(ReadmeFilename, ".md") + "." + locale + ".md"
+}
+
+func (c *Config) DecisionsPath() string {
+    return c.workDir + "/" + DecisionsFilename
+}
+
+func (c *Config) PromptTemplatePath() string {
+    if c.Classifier.Template != "" {
+        return c.WorkPath(c.Classifier.Template)
+    }
+    return c.workDir + "/" + PromptTemplateFilename
+}
+
+// WorkPath resolves the file name relative to the work dir
+func (c *Config) WorkPath(filename string) string {
+    if filepath.IsAbs(filename) {
+        return filename
+    }
+    return c.workDir + "/" + filename
+}
+
+func (c *Config) UsagePath() string {
+    return c.workDir + "/" + UsageFilename
+}
+
+func (c *Config) EmbeddingsPath() string {
+    return c.workDir + "/" + EmbeddingsFilename
+}
+
+func (c *Config) CachePath() string {
+    return c.workDir + "/" + CacheDirname
+}
+
 type CategoryDescription struct {
-    Title      string
-    Prompt     string
+    Title  string
+    Prompt string
+    // Include, Exclude and Examples are hints for the classifier: what belongs to the category, what doesn't,
+    // and example repos
+    Include  []string `yaml:"include,omitempty"`
+    Exclude  []string `yaml:"exclude,omitempty"`
+    Examples []string `yaml:"examples,omitempty"`
+    // Titles are the translations of the title for the readme locales
+    Titles     map[string]string `yaml:"titles,omitempty"`
     Categories []*CategoryDescription
 }
 
+// LocalTitle returns the title translated to the locale, or the title itself if there is no translation
+func (d *CategoryDescription) LocalTitle(locale string) string {
+    if t := d.Titles[locale]; t != "" {
+        return t
+    }
+    return d.Title
+}
+
 func (d *CategoryDescription) TitlesTree(depth int) (t []string) {
     if d == nil {
         return nil
@@ -113,6 +600,38 @@ func (d *CategoryDescription) Prompts() (p []string) {
     return p
 }
 
+// FindPath returns the categories from this one down to the one with the title, or nil if there is no such.
+func (d *CategoryDescription) FindPath(title string) []*CategoryDescription {
+    if d == nil {
+        return nil
+    }
+    if d.Title != "" && strings.Trim(d.Title, " ") == strings.Trim(title, " ") {
+        return []*CategoryDescription{d}
+    }
+    for _, sc := range d.Categories {
+        if path := sc.FindPath(title); path != nil {
+            return append([]*CategoryDescription{d}, path...)
+        }
+    }
+    return nil
+}
+
+func (d *CategoryDescription) FindPromptByTitle(title string) string {
+    if d == nil {
+        return ""
+    }
+    if d.Title != "" && strings.Trim(d.Title, " ") == strings.Trim(title, " ") {
+        return d.Prompt
+    }
+    for _, sc := range d.Categories {
+        prompt := sc.FindPromptByTitle(title)
+        if prompt != "" {
+            return prompt
+        }
+    }
+    return ""
+}
+
 func (d *CategoryDescription) FindTitleByPrompt(prompt string) string {
     if d == nil {
         return ""
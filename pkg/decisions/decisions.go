@@ -0,0 +1,108 @@
+package decisions
+
+import (
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "gopkg.in/yaml.v3"
+    "os"
+    "sync"
+    "time"
+)
+
+const IgnoredCategory = "(ignored)"
+
+// Decision is a single review result: what the classifier suggested and what the curator chose.
+type Decision struct {
+    Link          string    `yaml:"link"`
+    AICategory    string    `yaml:"ai_category"`
+    AIConfidence  float32   `yaml:"ai_confidence"`
+    Category      string    `yaml:"category"`
+    Ignored       bool      `yaml:"ignored"`
+    Reviewer      string    `yaml:"reviewer"`
+    Model         string    `yaml:"model"`
+    PromptVersion string    `yaml:"prompt_version"`
+    CreatedAt     time.Time `yaml:"created_at"`
+}
+
+func NewDecision(item *list.Item, reviewer string) *Decision {
+    return &Decision{
+        Link:          item.Link,
+        AICategory:    item.AICategory,
+        AIConfidence:  item.AICategoryConfidence,
+        Category:      item.Category,
+        Ignored:       item.Ignore,
+        Reviewer:      reviewer,
+        Model:         item.AIModel,
+        PromptVersion: item.AIPromptVersion,
+        CreatedAt:     time.Now(),
+    }
+}
+
+// Chosen returns the category picked by the curator, ignores are reported as IgnoredCategory.
+func (d *Decision) Chosen() string {
+    if d.Ignored {
+        return IgnoredCategory
+    }
+    return d.Category
+}
+
+func (d *Decision) Accepted() bool {
+    return !d.Ignored && d.AICategory != "" && d.AICategory == d.Category
+}
+
+type Log struct {
+    Decisions []*Decision
+}
+
+func NewFromFile(filename string) (*Log, error) {
+    bt, err := os.ReadFile(filename)
+    if os.IsNotExist(err) {
+        return &Log{}, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load decisions: %w", err)
+    }
+    l := Log{}
+    err = yaml.Unmarshal(bt, &l)
+    if err != nil {
+        return nil, fmt.Errorf("failed to unmarshal decisions: %w", err)
+    }
+    return &l, nil
+}
+
+func (l *Log) Save(filename string) error {
+    bt, err := yaml.Marshal(l)
+    if err != nil {
+        return fmt.Errorf("failed to marshal decisions: %w", err)
+    }
+    err = os.WriteFile(filename, bt, 0644)
+    if err != nil {
+        return fmt.Errorf("failed to save decisions: %w", err)
+    }
+    return nil
+}
+
+var appendMu sync.Mutex
+
+// Append adds the decision to the log file.
+func Append(filename string, d *Decision) error {
+    appendMu.Lock()
+    defer appendMu.Unlock()
+    l, err := NewFromFile(filename)
+    if err != nil {
+        return err
+    }
+    l.Decisions = append(l.Decisions, d)
+    return l.Save(filename)
+}
+
+// CurrentReviewer returns the name of the curator working in the terminal.
+func CurrentReviewer() string {
+    if r := os.Getenv("AWESOME_REVIEWER"); r != "" {
+        return r
+    }
+    if r := os.Getenv("USER"); r != "" {
+        return r
+    }
+    return "unknown"
+}
@@ -0,0 +1,272 @@
+package embeddings
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/base64"
+    "encoding/binary"
+    "encoding/hex"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "github.com/korchasa/awesome-toolkit/pkg/readme_preprocessor"
+    log "github.com/sirupsen/logrus"
+    "gopkg.in/yaml.v3"
+    "math"
+    "os"
+    "sort"
+    "strings"
+)
+
+const (
+    // readmeTokenLimit is the estimated tokens budget for the readme part of the embedded text
+    readmeTokenLimit = 500
+    batchSize        = 32
+)
+
+// Vector is stored in YAML as base64 of little-endian float32 values to keep the file compact.
+type Vector []float32
+
+func (v Vector) MarshalYAML() (interface{}, error) {
+    bt := make([]byte, 4*len(v))
+    for i, f := range v {
+        binary.LittleEndian.PutUint32(bt[4*i:], math.Float32bits(f))
+    }
+    return base64.StdEncoding.EncodeToString(bt), nil
+}
+
+func (v *Vector) UnmarshalYAML(node *yaml.Node) error {
+    bt, err := base64.StdEncoding.DecodeString(node.Value)
+    if err != nil {
+        return fmt.Errorf("failed to decode vector: %w", err)
+    }
+    if len(bt)%4 != 0 {
+        return fmt.Errorf("invalid vector length %d", len(bt))
+    }
+    *v = make(Vector, len(bt)/4)
+    for i := range *v {
+        (*v)[i] = math.Float32frombits(binary.LittleEndian.Uint32(bt[4*i:]))
+    }
+    return nil
+}
+
+type Entry struct {
+    Link     string `yaml:"link"`
+    Model    string `yaml:"model"`
+    TextHash string `yaml:"text_hash"`
+    Vector   Vector `yaml:"vector"`
+}
+
+// Index keeps the vectors of the curated items.
+type Index struct {
+    Entries []*Entry
+    byLink  map[string]*Entry
+}
+
+func NewFromFile(filename string) (*Index, error) {
+    bt, err := os.ReadFile(filename)
+    if os.IsNotExist(err) {
+        return &Index{}, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load embeddings: %w", err)
+    }
+    idx := Index{}
+    err = yaml.Unmarshal(bt, &idx)
+    if err != nil {
+        return nil, fmt.Errorf("failed to unmarshal embeddings: %w", err)
+    }
+    return &idx, nil
+}
+
+func (idx *Index) Save(filename string) error {
+    bt, err := yaml.Marshal(idx)
+    if err != nil {
+        return fmt.Errorf("failed to marshal embeddings: %w", err)
+    }
+    err = os.WriteFile(filename, bt, 0644)
+    if err != nil {
+        return fmt.Errorf("failed to save embeddings: %w", err)
+    }
+    return nil
+}
+
+func (idx *Index) find(link string) *Entry {
+    if idx.byLink == nil {
+        idx.byLink = map[string]*Entry{}
+        for _, e := range idx.Entries {
+            idx.byLink[e.Link] = e
+        }
+    }
+    return idx.byLink[link]
+}
+
+func (idx *Index) put(entry *Entry) {
+    if e := idx.find(entry.Link); e != nil {
+        *e = *entry
+        return
+    }
+    idx.Entries = append(idx.Entries, entry)
+    idx.byLink[entry.Link] = entry
+}
+
+type Neighbour struct {
+    Item       *list.Item
+    Similarity float64
+}
+
+// Suggester finds the curated items nearest to a candidate and lets them vote for its category.
+type Suggester struct {
+    embedder   llm.Embedder
+    index      *Index
+    indexPath  string
+    items      []*list.Item
+    readmeFor  func(*list.Item) string
+    neighbours int
+}
+
+func NewSuggester(
+    embedder llm.Embedder,
+    indexPath string,
+    items []*list.Item,
+    readmeFor func(*list.Item) string,
+    neighbours int,
+) (*Suggester, error) {
+    idx, err := NewFromFile(indexPath)
+    if err != nil {
+        return nil, err
+    }
+    var curated []*list.Item
+    for _, item := range items {
+        if !item.Ignore && !item.Pending && item.Category != "" {
+            curated = append(curated, item)
+        }
+    }
+    return &Suggester{
+        embedder:   embedder,
+        index:      idx,
+        indexPath:  indexPath,
+        items:      curated,
+        readmeFor:  readmeFor,
+        neighbours: neighbours,
+    }, nil
+}
+
+// Update embeds the curated items that have no vector yet, or whose text or model has changed.
+func (s *Suggester) Update(ctx context.Context) error {
+    var stale []*list.Item
+    var texts []string
+    for _, item := range s.items {
+        text := s.text(item, s.readmeFor(item))
+        e := s.index.find(item.Link)
+        if e == nil || e.Model != s.embedder.Model() || e.TextHash != hash(text) {
+            stale = append(stale, item)
+            texts = append(texts, text)
+        }
+    }
+    if len(stale) == 0 {
+        return nil
+    }
+    log.Infof("Embedding %d curated items", len(stale))
+    for start := 0; start < len(stale); start += batchSize {
+        end := start + batchSize
+        if end > len(stale) {
+            end = len(stale)
+        }
+        vectors, err := s.embedder.Embed(ctx, texts[start:end])
+        if err != nil {
+            return fmt.Errorf("failed to embed items: %w", err)
+        }
+        for i, item := range stale[start:end] {
+            s.index.put(&Entry{
+                Link:     item.Link,
+                Model:    s.embedder.Model(),
+                TextHash: hash(texts[start+i]),
+                Vector:   vectors[i],
+            })
+        }
+        if err := s.index.Save(s.indexPath); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// Suggest sets the nearest neighbours vote on the item and returns the neighbours, the most similar first.
+func (s *Suggester) Suggest(ctx context.Context, item *list.Item, readme string) ([]Neighbour, error) {
+    vectors, err := s.embedder.Embed(ctx, []string{s.text(item, readme)})
+    if err != nil {
+        return nil, fmt.Errorf("failed to embed item: %w", err)
+    }
+    neighbours := s.Nearest(vectors[0], item.Link, s.neighbours)
+
+    votes := map[string]float64{}
+    total := 0.0
+    for _, n := range neighbours {
+        if n.Similarity <= 0 {
+            continue
+        }
+        votes[n.Item.Category] += n.Similarity
+        total += n.Similarity
+    }
+    item.KNNCategory, item.KNNConfidence = "", 0
+    for cat, v := range votes {
+        confidence := float32(v / total)
+        if confidence > item.KNNConfidence || (confidence == item.KNNConfidence && cat < item.KNNCategory) {
+            item.KNNCategory, item.KNNConfidence = cat, confidence
+        }
+    }
+    return neighbours, nil
+}
+
+// Nearest returns k curated items with the highest cosine similarity to the vector, except the excluded one.
+func (s *Suggester) Nearest(vec []float32, exclude string, k int) []Neighbour {
+    var neighbours []Neighbour
+    for _, item := range s.items {
+        if item.Link == exclude {
+            continue
+        }
+        e := s.index.find(item.Link)
+        if e == nil || e.Model != s.embedder.Model() {
+            continue
+        }
+        neighbours = append(neighbours, Neighbour{Item: item, Similarity: Cosine(vec, e.Vector)})
+    }
+    sort.SliceStable(neighbours, func(i, j int) bool {
+        return neighbours[i].Similarity > neighbours[j].Similarity
+    })
+    if len(neighbours) > k {
+        neighbours = neighbours[:k]
+    }
+    return neighbours
+}
+
+func (s *Suggester) text(item *list.Item, readme string) string {
+    return strings.Join([]string{
+        item.Name,
+        item.Description,
+        item.AIDescription,
+        readme_preprocessor.Preprocess(readme, readmeTokenLimit),
+    }, "\n")
+}
+
+func Cosine(a, b []float32) float64 {
+    if len(a) != len(b) {
+        return 0
+    }
+    var dot, na, nb float64
+    for i := range a {
+        dot += float64(a[i]) * float64(b[i])
+        na += float64(a[i]) * float64(a[i])
+        nb += float64(b[i]) * float64(b[i])
+    }
+    if na == 0 || nb == 0 {
+        return 0
+    }
+    return dot / math.Sqrt(na*nb)
+}
+
+func hash(s string) string {
+    h := sha256.Sum256([]byte(s))
+    return hex.EncodeToString(h[:])[:16]
+}
@@ -2,16 +2,21 @@ package github
 
 import (
     "context"
+    "errors"
     "fmt"
     "github.com/google/go-github/v52/github"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
     "github.com/korchasa/awesome-toolkit/pkg/list"
     log "github.com/sirupsen/logrus"
+    "net/http"
     "strings"
     "time"
 )
 
 type GitHub struct {
-    client *github.Client
+    client  *github.Client
+    cache   *cache.Store
+    offline bool
 }
 
 func NewGitHub(token string) *GitHub {
@@ -20,22 +25,98 @@ func NewGitHub(token string) *GitHub {
     }
 }
 
+// WithCache makes fetched search results and readmes stored in the cache. In offline mode they are read from it
+// instead of GitHub.
+func (g *GitHub) WithCache(store *cache.Store, offline bool) *GitHub {
+    g.cache = store
+    g.offline = offline
+    return g
+}
+
 func (g *GitHub) SearchRepos(ctx context.Context, query string) ([]*list.Item, error) {
     var result []*list.Item
+    if g.offline {
+        err := g.fromCache(cache.KindSearch, query, &result)
+        return result, err
+    }
     err := g.search(ctx, &result, query, 1)
     if err != nil {
         return nil, fmt.Errorf("failed to search: %w", err)
     }
+    g.toCache(cache.KindSearch, query, result)
     return result, nil
 }
 
 func (g *GitHub) GetReadme(ctx context.Context, item *list.Item) (string, error) {
+    var readme string
+    if g.offline {
+        err := g.fromCache(cache.KindReadme, item.Link, &readme)
+        return readme, err
+    }
+    readme, err := g.fetchReadme(ctx, item)
+    if err != nil {
+        return "", err
+    }
+    g.toCache(cache.KindReadme, item.Link, readme)
+    return readme, nil
+}
+
+// GetRepo fetches the current metadata of the repository. It's never cached, it's what changes are noticed by.
+func (g *GitHub) GetRepo(ctx context.Context, item *list.Item) (*list.Item, error) {
+    if g.offline {
+        return nil, errors.New("repository metadata isn't available in offline mode")
+    }
+    parts := strings.Split(item.Name, "/")
+    if len(parts) != 2 {
+        return nil, fmt.Errorf("invalid repo name `%s`", item.Name)
+    }
+    r, _, err := g.client.Repositories.Get(ctx, parts[0], parts[1])
+    if err != nil {
+        return nil, fmt.Errorf("failed to get repo: %w", err)
+    }
+    return repoItem(r), nil
+}
+
+// CachedReadme returns the readme fetched earlier, or an empty string.
+func (g *GitHub) CachedReadme(item *list.Item) string {
+    var readme string
+    if g.cache == nil || g.cache.Get(cache.KindReadme, cache.Key(item.Link), &readme) != nil {
+        return ""
+    }
+    return readme
+}
+
+func (g *GitHub) fromCache(kind string, id string, v interface{}) error {
+    err := g.cache.Get(kind, cache.Key(id), v)
+    if errors.Is(err, cache.ErrMiss) {
+        return fmt.Errorf("no cached %s for `%s` in offline mode: %w", kind, id, err)
+    }
+    if err != nil {
+        return fmt.Errorf("failed to read %s cache: %w", kind, err)
+    }
+    return nil
+}
+
+func (g *GitHub) toCache(kind string, id string, v interface{}) {
+    if g.cache == nil {
+        return
+    }
+    if err := g.cache.Put(kind, cache.Key(id), v); err != nil {
+        log.Warnf("failed to write %s cache: %s", kind, err)
+    }
+}
+
+func (g *GitHub) fetchReadme(ctx context.Context, item *list.Item) (string, error) {
     parts := strings.Split(item.Name, "/")
     if len(parts) != 2 {
         log.Infof("invalid repo name: %s", item.Name)
         return "", nil
     }
-    readme, _, err := g.client.Repositories.GetReadme(ctx, parts[0], parts[1], nil)
+    readme, resp, err := g.client.Repositories.GetReadme(ctx, parts[0], parts[1], nil)
+    if resp != nil && resp.StatusCode == http.StatusNotFound {
+        // the repo has no readme, that's for the ignore rules to decide
+        return "", nil
+    }
     if err != nil {
         return "", fmt.Errorf("failed to get readme: %w", err)
     }
@@ -63,14 +144,7 @@ func (g *GitHub) search(ctx context.Context, result *[]*list.Item, query string,
         return fmt.Errorf("failed to search repos: %w", err)
     }
     for _, r := range rps.Repositories {
-        *result = append(*result, &list.Item{
-            Name:        limitString(safeGet(r.FullName), 500),
-            Link:        limitString(safeGet(r.HTMLURL), 500),
-            Description: limitString(safeGet(r.Description), 500),
-            Language:    limitString(safeGet(r.Language), 500),
-            IsNew:       true,
-            CreatedAt:   time.Now(),
-        })
+        *result = append(*result, repoItem(r))
     }
     log.Infof("found %d repos", len(rps.Repositories))
     if len(rps.Repositories) != 0 {
@@ -79,6 +153,22 @@ func (g *GitHub) search(ctx context.Context, result *[]*list.Item, query string,
     return nil
 }
 
+func repoItem(r *github.Repository) *list.Item {
+    return &list.Item{
+        Name:        limitString(safeGet(r.FullName), 500),
+        Link:        limitString(safeGet(r.HTMLURL), 500),
+        Description: limitString(safeGet(r.Description), 500),
+        Language:    limitString(safeGet(r.Language), 500),
+        Stars:       safeGet(r.StargazersCount),
+        Archived:    safeGet(r.Archived),
+        Fork:        safeGet(r.Fork),
+        License:     safeGet(safeGet(r.License).SPDXID),
+        PushedAt:    safeGet(r.PushedAt).Time,
+        IsNew:       true,
+        CreatedAt:   time.Now(),
+    }
+}
+
 func safeGet[T any](s *T) T {
     var empty T
     if s == nil {
@@ -1,51 +1,139 @@
 package ignorer
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/korchasa/awesome-toolkit/pkg/config"
+	"github.com/korchasa/awesome-toolkit/pkg/langdetect"
 	"github.com/korchasa/awesome-toolkit/pkg/list"
+	"github.com/korchasa/awesome-toolkit/pkg/readme_preprocessor"
 	log "github.com/sirupsen/logrus"
+	"path"
+	"regexp"
 	"strings"
-	"unicode"
 )
 
-const maxNonLatinSymbolsPercent = 3
+// proseTokenLimit is the estimated tokens budget of the readme text the language is detected by
+const proseTokenLimit = 1500
+
+// Verdict is a rule which fired on an item, with the explanation
+type Verdict struct {
+	Reason      string
+	Explanation string
+}
 
 type Ignorer struct {
+	rules []Rule
 }
 
-func NewIgnorer() *Ignorer {
-	return &Ignorer{}
+// NewIgnorer builds the rules enabled in the config. The first fired rule gives the ignore reason, so the
+// cheap and definite ones go first.
+func NewIgnorer(cfg *config.IgnoreConfig) (*Ignorer, error) {
+	var rules []Rule
+	for _, glob := range cfg.Blocklist {
+		if _, err := path.Match(glob, ""); err != nil {
+			return nil, fmt.Errorf("invalid blocklist glob `%s`: %w", glob, err)
+		}
+	}
+	if len(cfg.Blocklist) > 0 {
+		rules = append(rules, &blocklistRule{globs: cfg.Blocklist})
+	}
+	if len(cfg.Patterns) > 0 {
+		pr := &patternRule{}
+		for _, p := range cfg.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("invalid ignore pattern `%s`: %w", p, err)
+			}
+			pr.patterns = append(pr.patterns, re)
+		}
+		rules = append(rules, pr)
+	}
+	if cfg.Archived {
+		rules = append(rules, &archivedRule{})
+	}
+	if cfg.Forks {
+		rules = append(rules, &forkRule{})
+	}
+	if cfg.AwesomeLists {
+		rules = append(rules, &awesomeListRule{})
+	}
+	switch cfg.License {
+	case "":
+	case config.LicenseAny:
+		rules = append(rules, &noLicenseRule{})
+	case config.LicenseOSI:
+		rules = append(rules, &noLicenseRule{}, &osiLicenseRule{})
+	default:
+		return nil, fmt.Errorf(
+			"unknown license rule `%s`, expected `%s` or `%s`", cfg.License, config.LicenseAny, config.LicenseOSI,
+		)
+	}
+	if cfg.MinStars > 0 {
+		rules = append(rules, &starsRule{min: cfg.MinStars})
+	}
+	if cfg.MaxInactivityMonths > 0 {
+		rules = append(rules, &inactivityRule{months: cfg.MaxInactivityMonths})
+	}
+	if cfg.EmptyReadme {
+		rules = append(rules, &readmeRule{})
+	}
+	known := map[string]bool{}
+	for _, l := range langdetect.Languages() {
+		known[l] = true
+	}
+	languages := map[string]bool{}
+	for _, l := range cfg.Languages {
+		if !known[l] {
+			return nil, fmt.Errorf("unknown language `%s`, known are %s", l, strings.Join(langdetect.Languages(), ", "))
+		}
+		languages[l] = true
+	}
+	rules = append(rules, &languageRule{languages: languages, minConfidence: cfg.MinLanguageConfidence})
+	return &Ignorer{rules: rules}, nil
 }
 
-func (i *Ignorer) ResolveIgnores(item *list.Item, readme string) {
-	text := item.Description + readme
-	skipSyms := []string{"`", "*", "_", "{", "}", "[", "]", "(", ")", "#", "+", "-", ".", "!", "|"}
-	for _, sym := range skipSyms {
-		text = strings.ReplaceAll(text, sym, " ")
+// ResolveIgnores detects the language of the item and checks it by all the rules. The item is ignored with
+// the reason of the first fired rule, all the fired ones are returned.
+func (i *Ignorer) ResolveIgnores(item *list.Item, readme string) []Verdict {
+	item.ReadmeHash = ReadmeHash(readme)
+	detected := langdetect.Detect(item.Description + "\n\n" + readme_preprocessor.Prose(readme, proseTokenLimit))
+	item.DetectedLanguage = detected.Language
+	item.DetectedLanguageConfidence = float32(detected.Confidence)
+	var verdicts []Verdict
+	for _, r := range i.rules {
+		if explanation := r.Check(item, readme); explanation != "" {
+			verdicts = append(verdicts, Verdict{Reason: r.Reason(), Explanation: explanation})
+		}
 	}
-	p := nonLatinPercentage(text)
-	if p > maxNonLatinSymbolsPercent {
-		log.Infof("not-english `%s` (%f)", item.Link, p)
+	if len(verdicts) > 0 {
 		item.Ignore = true
-		item.IgnoreReason = "not-english"
+		item.IgnoreReason = verdicts[0].Reason
 	}
+	return verdicts
 }
 
-func nonLatinPercentage(s string) float64 {
-	if len(s) == 0 {
-		return 0
-	}
-	nonLatinCount := 0
-	totalChars := 0
-	for _, r := range s {
-		if unicode.IsLetter(r) {
-			totalChars++
-			if !unicode.In(r, unicode.Latin) {
-				nonLatinCount++
-			}
-		}
+// Explain logs all the rules fired on the item, the first one gives the reason
+func Explain(item *list.Item, verdicts []Verdict) {
+	if len(verdicts) == 0 {
+		log.Infof(
+			"`%s`: no ignore rule fired, language `%s` (%.2f)",
+			item.Name, item.DetectedLanguage, item.DetectedLanguageConfidence,
+		)
+		return
 	}
-	if totalChars == 0 {
-		return 0
+	for _, v := range verdicts {
+		log.Infof("`%s`: rule `%s` fired, %s", item.Name, v.Reason, v.Explanation)
+	}
+}
+
+// ReadmeHash returns the hash of the readme stored in the item, to tell whether the readme changed since the
+// rules checked it
+func ReadmeHash(readme string) string {
+	if readme == "" {
+		return ""
 	}
-	return float64(nonLatinCount) / float64(totalChars) * 100
+	sum := sha256.Sum256([]byte(readme))
+	return hex.EncodeToString(sum[:8])
 }
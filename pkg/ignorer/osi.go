@@ -0,0 +1,39 @@
+package ignorer
+
+// osiLicenses are the SPDX ids of the licenses approved by the Open Source Initiative, as reported by GitHub
+var osiLicenses = map[string]bool{
+	"0BSD":          true,
+	"AFL-3.0":       true,
+	"AGPL-3.0":      true,
+	"Apache-2.0":    true,
+	"Artistic-2.0":  true,
+	"BlueOak-1.0.0": true,
+	"BSD-2-Clause":  true,
+	"BSD-3-Clause":  true,
+	"BSL-1.0":       true,
+	"CDDL-1.0":      true,
+	"ECL-2.0":       true,
+	"EPL-1.0":       true,
+	"EPL-2.0":       true,
+	"EUPL-1.1":      true,
+	"EUPL-1.2":      true,
+	"GPL-2.0":       true,
+	"GPL-3.0":       true,
+	"ISC":           true,
+	"LGPL-2.1":      true,
+	"LGPL-3.0":      true,
+	"LPPL-1.3c":     true,
+	"MIT":           true,
+	"MIT-0":         true,
+	"MPL-2.0":       true,
+	"MS-PL":         true,
+	"MS-RL":         true,
+	"MulanPSL-2.0":  true,
+	"NCSA":          true,
+	"OFL-1.1":       true,
+	"OSL-3.0":       true,
+	"PostgreSQL":    true,
+	"UPL-1.0":       true,
+	"Unlicense":     true,
+	"Zlib":          true,
+}
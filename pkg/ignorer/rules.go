@@ -0,0 +1,250 @@
+package ignorer
+
+import (
+	"fmt"
+	"github.com/korchasa/awesome-toolkit/pkg/list"
+	"github.com/korchasa/awesome-toolkit/pkg/readme_preprocessor"
+	"path"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Ignore reasons, one per rule
+const (
+	ReasonBlocklist     = "blocklist"
+	ReasonPattern       = "pattern"
+	ReasonArchived      = "archived"
+	ReasonFork          = "fork"
+	ReasonAwesomeList   = "awesome-list"
+	ReasonNoLicense     = "no-license"
+	ReasonNonOSILicense = "non-osi-license"
+	ReasonStars         = "stars"
+	ReasonInactive      = "inactive"
+	ReasonEmptyReadme   = "empty-readme"
+	// ReasonLanguage is the ignore reason of the items written in a language which isn't allowed
+	ReasonLanguage = "language"
+	// ReasonNotEnglish is the ignore reason of the items with mostly non-latin texts, set before the language
+	// detection
+	ReasonNotEnglish = "not-english"
+)
+
+// expiring are the reasons which stop applying when the repository changes, the others are about what it is
+var expiring = map[string]bool{
+	ReasonArchived:      true,
+	ReasonNoLicense:     true,
+	ReasonNonOSILicense: true,
+	ReasonStars:         true,
+	ReasonInactive:      true,
+	ReasonEmptyReadme:   true,
+	ReasonLanguage:      true,
+	ReasonNotEnglish:    true,
+}
+
+// Expires reports whether the ignore reason can stop applying to the repository later
+func Expires(reason string) bool {
+	return expiring[reason]
+}
+
+// ByRule reports whether the ignore reason is set by a rule, the other reasons are given by curators
+func ByRule(reason string) bool {
+	switch reason {
+	case ReasonBlocklist, ReasonPattern, ReasonArchived, ReasonFork, ReasonAwesomeList, ReasonNoLicense,
+		ReasonNonOSILicense, ReasonStars, ReasonInactive, ReasonEmptyReadme, ReasonLanguage, ReasonNotEnglish:
+		return true
+	}
+	return false
+}
+
+// Rule decides whether a found repository is ignored
+type Rule interface {
+	// Reason is the ignore reason code the rule sets
+	Reason() string
+	// Check returns why the item must be ignored, or an empty string if it mustn't
+	Check(item *list.Item, readme string) string
+}
+
+type blocklistRule struct {
+	globs []string
+}
+
+func (r *blocklistRule) Reason() string { return ReasonBlocklist }
+
+func (r *blocklistRule) Check(item *list.Item, _ string) string {
+	name := strings.ToLower(item.Name)
+	for _, glob := range r.globs {
+		if ok, _ := path.Match(strings.ToLower(glob), name); ok {
+			return fmt.Sprintf("`%s` matches `%s`", item.Name, glob)
+		}
+	}
+	return ""
+}
+
+type patternRule struct {
+	patterns []*regexp.Regexp
+}
+
+func (r *patternRule) Reason() string { return ReasonPattern }
+
+func (r *patternRule) Check(item *list.Item, _ string) string {
+	for _, p := range r.patterns {
+		if m := p.FindString(item.Name + "\n" + item.Description); m != "" {
+			return fmt.Sprintf("`%s` matches `%s`", m, p)
+		}
+	}
+	return ""
+}
+
+type archivedRule struct{}
+
+func (r *archivedRule) Reason() string { return ReasonArchived }
+
+func (r *archivedRule) Check(item *list.Item, _ string) string {
+	if item.Archived {
+		return "the repo is archived"
+	}
+	return ""
+}
+
+type forkRule struct{}
+
+func (r *forkRule) Reason() string { return ReasonFork }
+
+func (r *forkRule) Check(item *list.Item, _ string) string {
+	if item.Fork {
+		return "the repo is a fork"
+	}
+	return ""
+}
+
+// minAwesomeLinks is the number of list links that makes a readme look like an awesome list
+const minAwesomeLinks = 20
+
+var (
+	awesomeNameRe    = regexp.MustCompile(`(?i)(^|[-_./])awesome([-_.]|$)`)
+	curatedRe        = regexp.MustCompile(`(?i)\b(curated|awesome) list\b`)
+	readmeListLinkRe = regexp.MustCompile(`(?m)^\s*[-*+]\s+\[[^\]]+\]\([^)]+\)`)
+)
+
+// awesomeListRule fires on two of the signs: an awesome name, a "curated list" description and a readme of links
+type awesomeListRule struct{}
+
+func (r *awesomeListRule) Reason() string { return ReasonAwesomeList }
+
+func (r *awesomeListRule) Check(item *list.Item, readme string) string {
+	var signs []string
+	if awesomeNameRe.MatchString(item.Name) {
+		signs = append(signs, "an awesome name")
+	}
+	if curatedRe.MatchString(item.Description) {
+		signs = append(signs, "a curated list description")
+	}
+	if n := len(readmeListLinkRe.FindAllString(readme, -1)); n >= minAwesomeLinks {
+		signs = append(signs, fmt.Sprintf("%d list links in the readme", n))
+	}
+	if len(signs) < 2 {
+		return ""
+	}
+	return "the repo is an awesome list: " + strings.Join(signs, ", ")
+}
+
+type noLicenseRule struct{}
+
+func (r *noLicenseRule) Reason() string { return ReasonNoLicense }
+
+func (r *noLicenseRule) Check(item *list.Item, _ string) string {
+	if item.License == "" {
+		return "the repo has no license"
+	}
+	return ""
+}
+
+type osiLicenseRule struct{}
+
+func (r *osiLicenseRule) Reason() string { return ReasonNonOSILicense }
+
+func (r *osiLicenseRule) Check(item *list.Item, _ string) string {
+	if item.License != "" && !osiLicenses[item.License] {
+		return fmt.Sprintf("license `%s` is not OSI approved", item.License)
+	}
+	return ""
+}
+
+type starsRule struct {
+	min int
+}
+
+func (r *starsRule) Reason() string { return ReasonStars }
+
+func (r *starsRule) Check(item *list.Item, _ string) string {
+	if item.Stars < r.min {
+		return fmt.Sprintf("%d stars, fewer than %d", item.Stars, r.min)
+	}
+	return ""
+}
+
+type inactivityRule struct {
+	months int
+}
+
+func (r *inactivityRule) Reason() string { return ReasonInactive }
+
+// Check doesn't fire when the last push is unknown
+func (r *inactivityRule) Check(item *list.Item, _ string) string {
+	if !item.PushedAt.IsZero() && item.PushedAt.Before(time.Now().AddDate(0, -r.months, 0)) {
+		return fmt.Sprintf("last push on %s, more than %d months ago", item.PushedAt.Format("2006-01-02"), r.months)
+	}
+	return ""
+}
+
+// minReadmeWords is the shortest readme prose worth a look
+const minReadmeWords = 10
+
+// templateReadmes are phrases of the readmes generated by project templates and never edited
+var templateReadmes = []string{
+	"this template should help get you started",
+	"getting started with create react app",
+	"this project was bootstrapped with",
+	"this project was generated with",
+	"a new flutter project",
+	"todo: give a short introduction of your project",
+	"edit a file, create a new file, and clone from bitbucket",
+	"you can use the editor on github to maintain and preview the content for your website",
+}
+
+type readmeRule struct{}
+
+func (r *readmeRule) Reason() string { return ReasonEmptyReadme }
+
+func (r *readmeRule) Check(_ *list.Item, readme string) string {
+	if strings.TrimSpace(readme) == "" {
+		return "the repo has no readme"
+	}
+	lower := strings.ToLower(readme)
+	for _, t := range templateReadmes {
+		if strings.Contains(lower, t) {
+			return fmt.Sprintf("the readme is a template: `%s`", t)
+		}
+	}
+	if n := len(strings.Fields(readme_preprocessor.Prose(readme, proseTokenLimit))); n < minReadmeWords {
+		return fmt.Sprintf("the readme has %d words of prose, fewer than %d", n, minReadmeWords)
+	}
+	return ""
+}
+
+// languageRule reads the language detected by Ignorer.ResolveIgnores
+type languageRule struct {
+	languages     map[string]bool
+	minConfidence float64
+}
+
+func (r *languageRule) Reason() string { return ReasonLanguage }
+
+// Check doesn't fire on uncertain detections, e.g. of a short text
+func (r *languageRule) Check(item *list.Item, _ string) string {
+	confidence := float64(item.DetectedLanguageConfidence)
+	if item.DetectedLanguage == "" || r.languages[item.DetectedLanguage] || confidence < r.minConfidence {
+		return ""
+	}
+	return fmt.Sprintf("language `%s` (%.2f) is not allowed", item.DetectedLanguage, confidence)
+}
@@ -0,0 +1,41 @@
+package injection
+
+import (
+    _ "embed"
+    "fmt"
+    "gopkg.in/yaml.v3"
+    "strings"
+)
+
+// targetPlaceholder is replaced with the category the fixture tries to steer the classifier to
+const targetPlaceholder = "{{target}}"
+
+//go:embed fixtures.yaml
+var fixturesSource []byte
+
+// Fixture is a readme of the adversarial regression suite
+type Fixture struct {
+    Name        string `yaml:"name"`
+    Description string `yaml:"description"`
+    Readme      string `yaml:"readme"`
+    // Flags are the expected result of the detection
+    Flags []string `yaml:"flags"`
+}
+
+func Fixtures() []Fixture {
+    var fixtures []Fixture
+    if err := yaml.Unmarshal(fixturesSource, &fixtures); err != nil {
+        panic(fmt.Errorf("invalid injection fixtures: %w", err))
+    }
+    return fixtures
+}
+
+// Suspicious reports whether the fixture is an injection attempt
+func (f Fixture) Suspicious() bool {
+    return len(f.Flags) > 0
+}
+
+// ReadmeFor returns the readme trying to steer the classifier to the target category
+func (f Fixture) ReadmeFor(target string) string {
+    return strings.ReplaceAll(f.Readme, targetPlaceholder, target)
+}
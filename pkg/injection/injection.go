@@ -0,0 +1,55 @@
+package injection
+
+import (
+    "regexp"
+    "sort"
+    "strings"
+)
+
+// pattern is an indicator of an attempt to instruct the model from the repository texts
+type pattern struct {
+    name string
+    re   *regexp.Regexp
+}
+
+// patterns match instructions aimed at the model, not the words alone: readmes describe output formats, lint
+// rules and chat APIs all the time
+var patterns = []pattern{
+    {"ignore-instructions", regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b[^.\n]{0,40}\b((previous|prior|above|earlier|system|your)\s+(instructions?|prompts?|rules|directions|guidelines)|(all|any)\s+(instructions|prompts))\b`)},
+    {"new-instructions", regexp.MustCompile(`(?i)\b(new|updated|real|actual|following)\s+(instructions?|system\s+prompt|rules)\s*:`)},
+    {"role-play", regexp.MustCompile(`(?i)\byou\s+are\s+(now|no\s+longer)\b|\bact\s+as\s+(an?\s+)?(ai|assistant|language\s+model|llm|chatgpt|gpt)\b|\bpretend\s+(to\s+be|you\s+are)\b`)},
+    {"addresses-model", regexp.MustCompile(`(?i)\b(dear|attention|note\s+to|hey|hello)\s*,?\s+(ai|llm|gpt|chatgpt|assistant|language\s+model|classifier|bot)s?\b|\b(ai|llm|gpt|language\s+model|classifier)s?\s+(reading|processing|classifying)\s+this\b`)},
+    {"dictates-answer", regexp.MustCompile(`(?i)\b(classify|categori[sz]e|label|put|assign|mark)\s+(this|the)\s+(repo|repository|project|library|tool)\b[^.\n]{0,40}\b(as|in(to)?|under|with)\b|\b(answer|respond|reply)\s+(with|using)\s+(the\s+)?(category|confidence)\b`)},
+    // answer-json is an object of the classifier answer schema, a single key is a config example
+    {"answer-json", regexp.MustCompile(`(?i)\{[^{}]{0,200}["']category["']\s*:[^{}]{0,200}["']confidence["']\s*:|\{[^{}]{0,200}["']confidence["']\s*:[^{}]{0,200}["']category["']\s*:`)},
+    {"role-tokens", regexp.MustCompile(`(?i)<\|\s*(im_start|im_end|im_sep|endoftext|system|user|assistant)\s*\|>|\[/?INST]|<</?SYS>>`)},
+    // fake-turn is a line pretending to be a message of the chat about the classification
+    {"fake-turn", regexp.MustCompile(`(?i)(^|\n)[ \t]*(assistant|system)[ \t]*:[^\n]{0,60}\b(category|categori[sz]e\w*|classif\w*)\b`)},
+    {"prompt-leak", regexp.MustCompile(`(?i)\b(reveal|print|show|repeat|output)\b[^.\n]{0,20}\b(system\s+prompt|your\s+(instructions|prompt))\b`)},
+}
+
+// Detect returns the names of the injection indicators found in the texts, sorted and unique
+func Detect(texts ...string) []string {
+    found := map[string]bool{}
+    for _, text := range texts {
+        for _, p := range patterns {
+            if !found[p.name] && p.re.MatchString(text) {
+                found[p.name] = true
+            }
+        }
+    }
+    var names []string
+    for name := range found {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+var escaper = strings.NewReplacer("<", "&lt;", ">", "&gt;", "```", "'''")
+
+// Escape makes the untrusted text unable to close the delimiters of the prompt or to open a new message with
+// role tokens. Angle brackets are escaped and code fences are replaced.
+func Escape(text string) string {
+    return escaper.Replace(text)
+}
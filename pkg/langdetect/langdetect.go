@@ -0,0 +1,255 @@
+// Package langdetect identifies the natural language of a text. The script tells Chinese, Japanese, Korean and
+// a few others apart, Latin and Cyrillic texts are scored by a naive Bayes model over character n-grams of
+// sample texts in the profiles directory.
+package langdetect
+
+import (
+    "embed"
+    "math"
+    "path"
+    "sort"
+    "strings"
+    "sync"
+    "unicode"
+)
+
+//go:embed profiles/*.txt
+var profileFiles embed.FS
+
+const (
+    // minLetters is the shortest text worth detecting
+    minLetters = 20
+    // maxGrams caps the n-grams scored per text, long texts are decided by their beginning
+    maxGrams = 3000
+    // maxGramLen is the longest n-gram
+    maxGramLen = 3
+    // sharpness scales the mean log-likelihood difference per n-gram before it's turned into a confidence
+    sharpness = 12
+)
+
+// Result is the detected language, an ISO 639-1 code, and the confidence of the detection from 0 to 1.
+// The language is empty if the text is too short.
+type Result struct {
+    Language   string
+    Confidence float64
+}
+
+const (
+    scriptLatin    = "latin"
+    scriptCyrillic = "cyrillic"
+    scriptHan      = "han"
+    scriptKana     = "kana"
+)
+
+// scripts are the scripts of the letters, the ones of a single language are named by the language
+var scripts = []struct {
+    name  string
+    table *unicode.RangeTable
+}{
+    {scriptLatin, unicode.Latin},
+    {scriptCyrillic, unicode.Cyrillic},
+    {scriptHan, unicode.Han},
+    {scriptKana, unicode.Hiragana},
+    {scriptKana, unicode.Katakana},
+    {"ko", unicode.Hangul},
+    {"ar", unicode.Arabic},
+    {"el", unicode.Greek},
+    {"he", unicode.Hebrew},
+    {"th", unicode.Thai},
+    {"hi", unicode.Devanagari},
+}
+
+type profile struct {
+    language string
+    script   string
+    logProb  map[string]float64
+    unseen   float64
+}
+
+var (
+    profiles    []*profile
+    profileOnce sync.Once
+)
+
+// Languages returns the codes of the languages the detector knows
+func Languages() []string {
+    seen := map[string]bool{"zh": true, "ja": true}
+    for _, s := range scripts {
+        if s.name != scriptLatin && s.name != scriptCyrillic && s.name != scriptHan && s.name != scriptKana {
+            seen[s.name] = true
+        }
+    }
+    for _, p := range loadProfiles() {
+        seen[p.language] = true
+    }
+    var languages []string
+    for l := range seen {
+        languages = append(languages, l)
+    }
+    sort.Strings(languages)
+    return languages
+}
+
+// Detect returns the language of the text. Letters of other scripts than the dominant one, e.g. a Chinese
+// example in an English readme, lower the confidence but don't change the language.
+func Detect(text string) Result {
+    counts := map[string]int{}
+    total := 0
+    for _, r := range text {
+        if s := scriptOf(r); s != "" {
+            counts[s]++
+            total++
+        }
+    }
+    if total < minLetters {
+        return Result{}
+    }
+    cjk := counts[scriptHan] + counts[scriptKana]
+    dominant, n := "", 0
+    for s, c := range counts {
+        if s == scriptHan || s == scriptKana {
+            continue
+        }
+        if c > n || (c == n && s < dominant) {
+            dominant, n = s, c
+        }
+    }
+    if cjk > n {
+        // Japanese mixes kanji with kana, Chinese has no kana
+        language := "zh"
+        if counts[scriptKana]*4 >= counts[scriptHan] {
+            language = "ja"
+        }
+        return Result{Language: language, Confidence: float64(cjk) / float64(total)}
+    }
+    share := float64(n) / float64(total)
+    if dominant != scriptLatin && dominant != scriptCyrillic {
+        return Result{Language: dominant, Confidence: share}
+    }
+    language, confidence := classify(grams(text, dominant, maxGrams), dominant)
+    return Result{Language: language, Confidence: confidence * share}
+}
+
+func scriptOf(r rune) string {
+    if !unicode.IsLetter(r) {
+        return ""
+    }
+    for _, s := range scripts {
+        if unicode.Is(s.table, r) {
+            return s.name
+        }
+    }
+    return ""
+}
+
+// classify scores the n-grams by the profiles of the script, the confidence is the softmax of the mean
+// log-likelihoods per n-gram
+func classify(gs []string, script string) (string, float64) {
+    if len(gs) == 0 {
+        return "", 0
+    }
+    var candidates []*profile
+    var scores []float64
+    best := math.Inf(-1)
+    for _, p := range loadProfiles() {
+        if p.script != script {
+            continue
+        }
+        score := 0.0
+        for _, g := range gs {
+            if lp, ok := p.logProb[g]; ok {
+                score += lp
+            } else {
+                score += p.unseen
+            }
+        }
+        score = score / float64(len(gs)) * sharpness
+        candidates = append(candidates, p)
+        scores = append(scores, score)
+        if score > best {
+            best = score
+        }
+    }
+    if len(candidates) == 0 {
+        return "", 0
+    }
+    sum, top := 0.0, 0
+    for i, s := range scores {
+        sum += math.Exp(s - best)
+        if s == best {
+            top = i
+        }
+    }
+    return candidates[top].language, 1 / sum
+}
+
+// grams returns the character n-grams of the words of the script, each word padded with spaces, at most limit
+// ones unless the limit is zero
+func grams(text string, script string, limit int) []string {
+    var gs []string
+    for _, word := range strings.FieldsFunc(strings.ToLower(text), func(r rune) bool { return scriptOf(r) != script }) {
+        padded := []rune(" " + word + " ")
+        for size := 1; size <= maxGramLen; size++ {
+            for i := 0; i+size <= len(padded); i++ {
+                g := string(padded[i : i+size])
+                if g != " " {
+                    gs = append(gs, g)
+                }
+            }
+        }
+        if limit > 0 && len(gs) >= limit {
+            return gs[:limit]
+        }
+    }
+    return gs
+}
+
+// loadProfiles builds the n-gram models from the sample texts, the smoothing uses the vocabulary of all
+// profiles of the script so the scores are comparable
+func loadProfiles() []*profile {
+    profileOnce.Do(func() {
+        entries, err := profileFiles.ReadDir("profiles")
+        if err != nil {
+            panic(err)
+        }
+        counts := map[*profile]map[string]int{}
+        vocabulary := map[string]map[string]bool{}
+        for _, e := range entries {
+            bt, err := profileFiles.ReadFile(path.Join("profiles", e.Name()))
+            if err != nil {
+                panic(err)
+            }
+            text := string(bt)
+            script := scriptLatin
+            for _, r := range text {
+                if s := scriptOf(r); s != "" {
+                    script = s
+                    break
+                }
+            }
+            p := &profile{language: strings.TrimSuffix(e.Name(), ".txt"), script: script}
+            counts[p] = map[string]int{}
+            if vocabulary[script] == nil {
+                vocabulary[script] = map[string]bool{}
+            }
+            for _, g := range grams(text, script, 0) {
+                counts[p][g]++
+                vocabulary[script][g] = true
+            }
+            profiles = append(profiles, p)
+        }
+        for _, p := range profiles {
+            total := 0
+            for _, c := range counts[p] {
+                total += c
+            }
+            denominator := float64(total) + 0.5*float64(len(vocabulary[p.script]))
+            p.logProb = map[string]float64{}
+            for g, c := range counts[p] {
+                p.logProb[g] = math.Log((float64(c) + 0.5) / denominator)
+            }
+            p.unseen = math.Log(0.5 / denominator)
+        }
+    })
+    return profiles
+}
@@ -0,0 +1,90 @@
+package langdetect
+
+import (
+    "testing"
+)
+
+func TestDetect(t *testing.T) {
+    tests := []struct {
+        name string
+        text string
+        want string
+    }{
+        {
+            "en",
+            "A fast key-value store written in Go. It keeps the data in memory and writes snapshots to the disk, " +
+                "so the service can be restarted without losing anything.",
+            "en",
+        },
+        {
+            "es",
+            "Una biblioteca rápida para procesar archivos de configuración. Permite validar los datos y " +
+                "generar la documentación de forma automática desde la línea de comandos.",
+            "es",
+        },
+        {
+            "de",
+            "Eine schnelle Bibliothek zum Verarbeiten von Konfigurationsdateien. Sie prüft die Daten und " +
+                "erzeugt die Dokumentation automatisch über die Kommandozeile.",
+            "de",
+        },
+        {
+            "vi",
+            "Thư viện nhanh để xử lý các tệp cấu hình. Nó kiểm tra dữ liệu và tự động tạo tài liệu " +
+                "từ dòng lệnh cho người dùng.",
+            "vi",
+        },
+        {
+            "ru",
+            "Быстрая библиотека для обработки файлов конфигурации. Она проверяет данные и автоматически " +
+                "создаёт документацию из командной строки.",
+            "ru",
+        },
+        {"zh", "一个用于处理配置文件的快速库，它可以验证数据并自动从命令行生成文档。", "zh"},
+        {"ja", "設定ファイルを処理するための高速なライブラリです。データを検証し、ドキュメントを自動的に生成します。", "ja"},
+        {
+            "en with a CJK example",
+            "A library to segment text into words. It supports many languages and runs in the browser.\n\n" +
+                "## Example\n\n```\nsegment(\"我爱北京天安门\")\n```\n\nThe result is a list of words with " +
+                "their positions in the text.",
+            "en",
+        },
+        {
+            "en with a Cyrillic author name",
+            "A tiny HTTP router with zero allocations. Routes are matched by a radix tree and the handlers get " +
+                "the parameters without any reflection.\n\nWritten by Иван Петров.",
+            "en",
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := Detect(tt.text)
+            if got.Language != tt.want {
+                t.Errorf("Detect() = %+v, want %s", got, tt.want)
+            }
+            if got.Confidence <= 0 || got.Confidence > 1 {
+                t.Errorf("confidence = %v, want in (0, 1]", got.Confidence)
+            }
+        })
+    }
+}
+
+func TestDetectShortText(t *testing.T) {
+    for _, text := range []string{"", "A Go library", "Быстрый роутер", "12345 67890 !!! ???", "高速ルーター"} {
+        if got := Detect(text); got != (Result{}) {
+            t.Errorf("Detect(%q) = %+v, want no language", text, got)
+        }
+    }
+}
+
+func TestLanguages(t *testing.T) {
+    languages := map[string]bool{}
+    for _, l := range Languages() {
+        languages[l] = true
+    }
+    for _, l := range []string{"en", "es", "de", "vi", "ru", "zh", "ja", "ko"} {
+        if !languages[l] {
+            t.Errorf("Languages() = %v, misses %s", Languages(), l)
+        }
+    }
+}
@@ -4,6 +4,7 @@ import (
     "fmt"
     "gopkg.in/yaml.v3"
     "os"
+    "strings"
     "time"
 )
 
@@ -11,6 +12,9 @@ type List struct {
     UpdatedAt         time.Time `yaml:"updated_at"`
     ReadmeGeneratedAt time.Time `yaml:"readme_generated_at"`
     Items             []*Item
+    // revisions are the revisions of the items in the files the list was loaded from or saved to, by file and
+    // link, to tell the changes of other commands when saving
+    revisions map[string]map[string]int
 }
 
 func NewEmpty() *List {
@@ -31,18 +35,37 @@ func NewFromFile(filename string) (l *List, err error) {
     if err != nil {
         return nil, fmt.Errorf("failed to unmarshal config: %w", err)
     }
+    for _, item := range cfg.Items {
+        for _, field := range item.Locks {
+            if !lockableFields[field] && !strings.HasPrefix(field, FieldTranslations+".") {
+                return nil, fmt.Errorf("item `%s` locks unknown field `%s`", item.Link, field)
+            }
+        }
+    }
+    cfg.remember(filename)
     return &cfg, nil
 }
 
+// Save writes the list to the file, keeping the changes other commands saved to it meanwhile, see merge
 func (l *List) Save(filename string) error {
+    if err := l.merge(filename); err != nil {
+        return err
+    }
     bt, err := yaml.Marshal(l)
     if err != nil {
         return fmt.Errorf("failed to marshal list: %w", err)
     }
-    err = os.WriteFile(filename, bt, 0644)
+    // write to a temporary file first, so concurrent readers never see a partially written list
+    tmp := filename + ".part"
+    err = os.WriteFile(tmp, bt, 0644)
+    if err != nil {
+        return fmt.Errorf("failed to save list: %w", err)
+    }
+    err = os.Rename(tmp, filename)
     if err != nil {
         return fmt.Errorf("failed to save list: %w", err)
     }
+    l.remember(filename)
     return nil
 }
 
@@ -59,19 +82,199 @@ func (l *List) Add(item *Item) {
     l.Items = append(l.Items, item)
 }
 
+func (l *List) FindByLink(link string) *Item {
+    for _, i := range l.Items {
+        if i.Link == link {
+            return i
+        }
+    }
+    return nil
+}
+
+func (l *List) Pending() (items []*Item) {
+    for _, i := range l.Items {
+        if i.Pending {
+            items = append(items, i)
+        }
+    }
+    return items
+}
+
 type Item struct {
-    Name                 string    `yaml:"name"`
-    Link                 string    `yaml:"link"`
-    Description          string    `yaml:"description"`
-    Ignore               bool      `yaml:"ignore"`
-    IgnoreReason         string    `yaml:"ignore_reason"`
-    Category             string    `yaml:"category"`
-    Language             string    `yaml:"language"`
-    AICategory           string    `yaml:"ai_category"`
-    AICategoryConfidence float32   `yaml:"ai_category_confidence"`
-    AIDescription        string    `yaml:"ai_description"`
-    CreatedAt            time.Time `yaml:"created_at"`
-    IsNew                bool      `yaml:"is_new"`
+    Name               string `yaml:"name"`
+    Link               string `yaml:"link"`
+    Description        string `yaml:"description"`
+    CuratorDescription string `yaml:"curator_description,omitempty"`
+    Ignore             bool   `yaml:"ignore"`
+    IgnoreReason       string `yaml:"ignore_reason"`
+    Category           string `yaml:"category"`
+    Language           string `yaml:"language"`
+    // DetectedLanguage is the natural language of the readme and the description, Language is the programming one
+    DetectedLanguage           string       `yaml:"detected_language,omitempty"`
+    DetectedLanguageConfidence float32      `yaml:"detected_language_confidence,omitempty"`
+    Stars                      int          `yaml:"stars,omitempty"`
+    Archived                   bool         `yaml:"archived,omitempty"`
+    Fork                       bool         `yaml:"fork,omitempty"`
+    License                    string       `yaml:"license,omitempty"` // SPDX id
+    PushedAt                   time.Time    `yaml:"pushed_at,omitempty"`
+    ReadmeHash                 string       `yaml:"readme_hash,omitempty"` // of the readme the rules checked
+    RevisitedAt                time.Time    `yaml:"revisited_at,omitempty"`
+    AICategory                 string       `yaml:"ai_category"`
+    AICategoryConfidence       float32      `yaml:"ai_category_confidence"`
+    AIDescription              string       `yaml:"ai_description"`
+    AIModel                    string       `yaml:"ai_model"`
+    AIPromptVersion            string       `yaml:"ai_prompt_version"`
+    AISuggestions              []Suggestion `yaml:"ai_suggestions,omitempty"`
+    AIOpinions                 []Opinion    `yaml:"ai_opinions,omitempty"`
+    AIDisagreement             bool         `yaml:"ai_disagreement,omitempty"`
+    AIAssessment               *Assessment  `yaml:"ai_assessment,omitempty"`
+    InjectionFlags             []string     `yaml:"injection_flags,omitempty"`
+    KNNCategory                string       `yaml:"knn_category,omitempty"`
+    KNNConfidence              float32      `yaml:"knn_confidence,omitempty"`
+    Similar                    []Similar    `yaml:"similar,omitempty"`
+    CreatedAt                  time.Time    `yaml:"created_at"`
+    IsNew                      bool         `yaml:"is_new"`
+    Pending                    bool         `yaml:"pending"`
+    Revision                   int          `yaml:"revision"`
+    // Translations are the descriptions translated to the readme locales, by locale
+    Translations map[string]*Translation `yaml:"translations,omitempty"`
+    // Locks are the fields edited by curators, automated writers must not overwrite them
+    Locks []string `yaml:"locks,omitempty"`
+}
+
+// Translation is the description of the item translated to a readme locale
+type Translation struct {
+    Description string `yaml:"description"`
+    // Source is the translated text, the translation is outdated when the description differs
+    Source string `yaml:"source"`
+    Model  string `yaml:"model,omitempty"`
+}
+
+// Suggestion is one of the ranked categories proposed by the classifier, with the reason it fits
+type Suggestion struct {
+    Category   string  `yaml:"category"`
+    Confidence float32 `yaml:"confidence"`
+    Rationale  string  `yaml:"rationale,omitempty"`
+}
+
+// Similar is a curated item close to the item by embeddings, shown to the reviewers
+type Similar struct {
+    Name       string  `yaml:"name"`
+    Link       string  `yaml:"link"`
+    Category   string  `yaml:"category"`
+    Similarity float32 `yaml:"similarity"`
+}
+
+// Fields which can be locked, named as in the data file
+const (
+    FieldDescription   = "description"
+    FieldCategory      = "category"
+    FieldAICategory    = "ai_category"
+    FieldAIDescription = "ai_description"
+    FieldAIAssessment  = "ai_assessment"
+    FieldTranslations  = "translations"
+)
+
+// TranslationField returns the lock name of the translation to the locale, e.g. `translations.ru`
+func TranslationField(locale string) string {
+    return FieldTranslations + "." + locale
+}
+
+var lockableFields = map[string]bool{
+    FieldDescription:   true,
+    FieldCategory:      true,
+    FieldAICategory:    true,
+    FieldAIDescription: true,
+    FieldAIAssessment:  true,
+}
+
+// Opinion is the answer of one of the classifiers of the consensus mode
+type Opinion struct {
+    Model         string  `yaml:"model"`
+    PromptVersion string  `yaml:"prompt_version,omitempty"`
+    Category      string  `yaml:"category"`
+    Confidence    float32 `yaml:"confidence"`
+    Rationale     string  `yaml:"rationale,omitempty"`
+}
+
+// Assessment is the AI estimation of the repository: how mature it is, what kind of project it is, its notable
+// strengths and caveats
+type Assessment struct {
+    Maturity  string   `yaml:"maturity"`
+    Kind      string   `yaml:"kind"`
+    Strengths []string `yaml:"strengths,omitempty"`
+    Caveats   []string `yaml:"caveats,omitempty"`
+    Model     string   `yaml:"model,omitempty"`
+}
+
+// Suggestions returns the ranked suggestions of the item. Items classified before the suggestions were
+// introduced have only the AI category.
+func (i *Item) Suggestions() []Suggestion {
+    if len(i.AISuggestions) > 0 {
+        return i.AISuggestions
+    }
+    if i.AICategory == "" {
+        return nil
+    }
+    return []Suggestion{{Category: i.AICategory, Confidence: i.AICategoryConfidence}}
+}
+
+// Locked checks that the field is locked by a curator
+func (i *Item) Locked(field string) bool {
+    for _, l := range i.Locks {
+        if l == field {
+            return true
+        }
+    }
+    return false
+}
+
+// UpdateAIFields copies the AI results of the candidate, an item classified from the inputs of this one, except
+// the fields locked by curators. The results the candidate has no value for, e.g. of a skipped classification,
+// are kept.
+func (i *Item) UpdateAIFields(candidate *Item) {
+    if candidate.AIModel != "" {
+        if !i.Locked(FieldAICategory) {
+            i.AICategory = candidate.AICategory
+            i.AICategoryConfidence = candidate.AICategoryConfidence
+            i.AISuggestions = candidate.AISuggestions
+        }
+        i.AIModel = candidate.AIModel
+        i.AIPromptVersion = candidate.AIPromptVersion
+        i.AIOpinions = candidate.AIOpinions
+        i.AIDisagreement = candidate.AIDisagreement
+    }
+    if candidate.AIModel != "" || candidate.Suspicious() {
+        i.InjectionFlags = candidate.InjectionFlags
+    }
+    if candidate.AIAssessment != nil && !i.Locked(FieldAIAssessment) {
+        i.AIAssessment = candidate.AIAssessment
+    }
+    if candidate.AIDescription != "" && !i.Locked(FieldAIDescription) {
+        i.AIDescription = candidate.AIDescription
+    }
+}
+
+// Translation returns the description translated to the locale, if the translation is reviewed by a curator or
+// made from the source description
+func (i *Item) Translation(locale string, source string) string {
+    t := i.Translations[locale]
+    if t == nil || (t.Source != source && !i.Locked(TranslationField(locale))) {
+        return ""
+    }
+    return t.Description
+}
+
+// Suspicious checks that the texts of the item look like a prompt injection, so the AI suggestions can't be
+// trusted and a curator must choose the category
+func (i *Item) Suspicious() bool {
+    return len(i.InjectionFlags) > 0
+}
+
+// NeedsReview checks that the AI category of the item must not be accepted without a curator: the texts are
+// suspicious or the classifiers of the consensus mode disagree
+func (i *Item) NeedsReview() bool {
+    return i.Suspicious() || i.AIDisagreement
 }
 
 func (i *Item) String() string {
@@ -0,0 +1,37 @@
+package list
+
+import (
+    "testing"
+)
+
+func TestUpdateAIFieldsKeepsLocks(t *testing.T) {
+    item := &Item{
+        AICategory:    "Tools",
+        AIDescription: "reviewed description",
+        Locks:         []string{FieldAIDescription},
+    }
+    item.UpdateAIFields(&Item{
+        AICategory:    "Databases",
+        AIDescription: "model description",
+        AIModel:       "model",
+        AIAssessment:  &Assessment{Maturity: "stable"},
+    })
+    if item.AIDescription != "reviewed description" {
+        t.Errorf("locked description = %q, want the reviewed one", item.AIDescription)
+    }
+    if item.AICategory != "Databases" || item.AIModel != "model" || item.AIAssessment == nil {
+        t.Errorf("item = %+v, want the unlocked fields updated", item)
+    }
+}
+
+func TestUpdateAIFieldsKeepsSkippedResults(t *testing.T) {
+    item := &Item{AICategory: "Tools", AIModel: "earlier", InjectionFlags: []string{"role-play"}}
+    // the classification was skipped, only the assessment ran
+    item.UpdateAIFields(&Item{AIAssessment: &Assessment{Maturity: "stable"}})
+    if item.AICategory != "Tools" || item.AIModel != "earlier" || len(item.InjectionFlags) != 1 {
+        t.Errorf("item = %+v, want the earlier classification kept", item)
+    }
+    if item.AIAssessment == nil {
+        t.Errorf("the assessment is not copied")
+    }
+}
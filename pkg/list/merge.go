@@ -0,0 +1,79 @@
+package list
+
+import (
+    "fmt"
+    log "github.com/sirupsen/logrus"
+    "gopkg.in/yaml.v3"
+    "os"
+)
+
+// merge adds the changes other commands saved to the file since the list was loaded from it or saved to it.
+// Items are matched by link, an item whose revision in the file differs from the known one was changed by
+// another command: its version wins, and the item is copied in place so the caller's pointers see it. Items
+// added to the file are appended, items removed from it are dropped.
+func (l *List) merge(filename string) error {
+    bt, err := os.ReadFile(filename)
+    if os.IsNotExist(err) {
+        return nil
+    }
+    if err != nil {
+        return fmt.Errorf("failed to read list: %w", err)
+    }
+    var saved List
+    if err := yaml.Unmarshal(bt, &saved); err != nil {
+        return fmt.Errorf("failed to unmarshal list: %w", err)
+    }
+    theirs := map[string]*Item{}
+    for _, item := range saved.Items {
+        if _, ok := theirs[item.Link]; !ok {
+            theirs[item.Link] = item
+        }
+    }
+    known := l.revisions[filename]
+    ours := map[string]bool{}
+    var items []*Item
+    for _, item := range l.Items {
+        ours[item.Link] = true
+        base, loaded := known[item.Link]
+        t, inFile := theirs[item.Link]
+        switch {
+        case !inFile && loaded:
+            if item.Revision != base {
+                log.Warnf("`%s` was removed by another command, its changes are dropped", item.Link)
+            }
+            continue
+        case inFile && (!loaded || t.Revision != base):
+            if loaded && item.Revision != base {
+                log.Warnf("`%s` was changed by another command, its changes here are dropped", item.Link)
+            }
+            *item = *t
+        }
+        items = append(items, item)
+    }
+    for _, t := range saved.Items {
+        if ours[t.Link] {
+            continue
+        }
+        base, loaded := known[t.Link]
+        if loaded && t.Revision == base {
+            // removed here
+            continue
+        }
+        ours[t.Link] = true
+        items = append(items, t)
+    }
+    l.Items = items
+    return nil
+}
+
+// remember records the revisions of the items as they are in the file
+func (l *List) remember(filename string) {
+    if l.revisions == nil {
+        l.revisions = map[string]map[string]int{}
+    }
+    known := map[string]int{}
+    for _, item := range l.Items {
+        known[item.Link] = item.Revision
+    }
+    l.revisions[filename] = known
+}
@@ -0,0 +1,121 @@
+package list
+
+import (
+    "path/filepath"
+    "testing"
+)
+
+func mustLoad(t *testing.T, filename string) *List {
+    t.Helper()
+    l, err := NewFromFile(filename)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return l
+}
+
+func mustSave(t *testing.T, l *List, filename string) {
+    t.Helper()
+    if err := l.Save(filename); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func links(l *List) (result []string) {
+    for _, item := range l.Items {
+        result = append(result, item.Link)
+    }
+    return result
+}
+
+func TestSaveKeepsChangesOfOtherCommands(t *testing.T) {
+    filename := filepath.Join(t.TempDir(), "data.yaml")
+    initial := &List{Items: []*Item{
+        {Link: "a", Category: "Tools"},
+        {Link: "b", Category: "Tools"},
+        {Link: "c", Category: "Tools"},
+        {Link: "d", Category: "Tools"},
+    }}
+    mustSave(t, initial, filename)
+
+    // a long running command loads the list
+    ours := mustLoad(t, filename)
+    a := ours.FindByLink("a")
+
+    // meanwhile a reviewer changes `a` and `b`, removes `d` and adds `e`
+    theirs := mustLoad(t, filename)
+    theirs.FindByLink("a").Category = "Libraries"
+    theirs.FindByLink("a").Revision++
+    theirs.FindByLink("b").Ignore = true
+    theirs.FindByLink("b").Revision++
+    theirs.Items = theirs.Items[:3]
+    theirs.Add(&Item{Link: "e", Category: "Tools"})
+    mustSave(t, theirs, filename)
+
+    // the command changes `a` and `c`, removes `b` and adds `f`
+    a.AIDescription = "changed here"
+    a.Revision++
+    ours.FindByLink("c").AIDescription = "changed here"
+    ours.FindByLink("c").Revision++
+    ours.Items = []*Item{ours.Items[0], ours.Items[2], ours.Items[3]}
+    ours.Add(&Item{Link: "f"})
+    mustSave(t, ours, filename)
+
+    result := mustLoad(t, filename)
+    want := []string{"a", "c", "b", "e", "f"}
+    got := links(result)
+    if len(got) != len(want) {
+        t.Fatalf("links = %v, want the items of %v", got, want)
+    }
+    for _, link := range want {
+        if result.FindByLink(link) == nil {
+            t.Errorf("`%s` is missing, links = %v", link, got)
+        }
+    }
+    if item := result.FindByLink("a"); item.Category != "Libraries" || item.AIDescription != "" {
+        t.Errorf("a = %+v, want the reviewer's version", item)
+    }
+    if a.Category != "Libraries" {
+        t.Errorf("the command's pointer to `a` isn't updated: %+v", a)
+    }
+    if item := result.FindByLink("b"); !item.Ignore {
+        t.Errorf("b = %+v, want the reviewer's version kept though it was removed here", item)
+    }
+    if item := result.FindByLink("c"); item.AIDescription != "changed here" {
+        t.Errorf("c = %+v, want the change made here", item)
+    }
+    if result.FindByLink("d") != nil {
+        t.Errorf("d was removed by the reviewer and must stay removed")
+    }
+}
+
+func TestSaveRemovesItemsUnchangedByOthers(t *testing.T) {
+    filename := filepath.Join(t.TempDir(), "data.yaml")
+    mustSave(t, &List{Items: []*Item{{Link: "a"}, {Link: "b"}}}, filename)
+    ours := mustLoad(t, filename)
+    ours.Items = ours.Items[:1]
+    mustSave(t, ours, filename)
+    mustSave(t, ours, filename)
+    if got := links(mustLoad(t, filename)); len(got) != 1 || got[0] != "a" {
+        t.Errorf("links = %v, want [a]", got)
+    }
+}
+
+func TestSaveToAnotherFile(t *testing.T) {
+    dir := t.TempDir()
+    data := filepath.Join(dir, "data.yaml")
+    temp := filepath.Join(dir, "data.tmp.yaml")
+    mustSave(t, &List{Items: []*Item{{Link: "a"}}}, data)
+
+    // the collector saves found items to a temporary file before the data file
+    ours := mustLoad(t, data)
+    ours.Add(&Item{Link: "b"})
+    mustSave(t, ours, temp)
+    ours.Add(&Item{Link: "c"})
+    mustSave(t, ours, temp)
+    mustSave(t, ours, data)
+
+    if got := links(mustLoad(t, data)); len(got) != 3 {
+        t.Errorf("links = %v, want [a b c]", got)
+    }
+}
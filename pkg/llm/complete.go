@@ -0,0 +1,82 @@
+package llm
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
+    log "github.com/sirupsen/logrus"
+)
+
+// RepairPrompt asks the model to fix an invalid answer, the error of the answer is quoted
+const RepairPrompt = "Your answer is invalid: %s. Answer again with a single JSON object that matches the schema, without any explanations."
+
+// CompleteJSON sends the request and parses the answer. An invalid answer is shown back to the model with the
+// parse error, and the model is asked again up to the given number of repair attempts.
+func CompleteJSON[T any](
+    ctx context.Context, ai LLM, req Request, parse func(string) (T, error), attempts int,
+) (T, error) {
+    var zero T
+    req.Messages = append([]Message{}, req.Messages...)
+    for attempt := 0; ; attempt++ {
+        resp, err := ai.Complete(ctx, req)
+        if err != nil {
+            return zero, fmt.Errorf("failed to create chat completion: %w", err)
+        }
+        log.Debugf("%s response: %+v", ai.Model(), resp.Content)
+        answer, err := parse(resp.Content)
+        if err == nil {
+            return answer, nil
+        }
+        if attempt >= attempts {
+            return zero, fmt.Errorf("failed to parse response: %w: %s", err, resp.Content)
+        }
+        log.Warnf("invalid %s response, asking again: %s", ai.Model(), err)
+        req.Messages = append(
+            req.Messages,
+            Message{Role: RoleAssistant, Content: resp.Content},
+            Message{Role: RoleUser, Content: fmt.Sprintf(RepairPrompt, err)},
+        )
+    }
+}
+
+// Cache is where CachedJSON keeps the answers
+type Cache struct {
+    // Store is the cache, nil disables caching
+    Store *cache.Store
+    // Kind is the kind of the cached answers, e.g. cache.KindTranslation
+    Kind string
+    // Key identifies the answer, usually cache.Key of the prompt, the input and the model
+    Key string
+    // Offline only uses cached answers and never asks the model
+    Offline bool
+}
+
+// CachedJSON returns the cached answer or asks the model by CompleteJSON and caches the answer
+func CachedJSON[T any](
+    ctx context.Context, ai LLM, c Cache, req Request, parse func(string) (T, error), attempts int,
+) (T, error) {
+    var zero T
+    if c.Store == nil {
+        return CompleteJSON(ctx, ai, req, parse, attempts)
+    }
+    var cached T
+    err := c.Store.Get(c.Kind, c.Key, &cached)
+    if err == nil {
+        return cached, nil
+    }
+    if !errors.Is(err, cache.ErrMiss) {
+        log.Warnf("failed to read %s cache: %s", c.Kind, err)
+    }
+    if c.Offline {
+        return zero, fmt.Errorf("no cached %s in offline mode: %w", c.Kind, cache.ErrMiss)
+    }
+    answer, err := CompleteJSON(ctx, ai, req, parse, attempts)
+    if err != nil {
+        return zero, err
+    }
+    if err := c.Store.Put(c.Kind, c.Key, answer); err != nil {
+        log.Warnf("failed to write %s cache: %s", c.Kind, err)
+    }
+    return answer, nil
+}
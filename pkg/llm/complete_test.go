@@ -0,0 +1,66 @@
+package llm
+
+import (
+    "context"
+    "errors"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
+    "strings"
+    "testing"
+)
+
+func parseAnswer(content string) (string, error) {
+    if !strings.HasPrefix(content, "{") {
+        return "", errors.New("not a JSON object")
+    }
+    return content, nil
+}
+
+func TestCompleteJSONRepairs(t *testing.T) {
+    ai := NewFake("fake", "sure!", `{"a":1}`)
+    req := Request{Messages: []Message{{Role: RoleUser, Content: "question"}}}
+    answer, err := CompleteJSON(context.Background(), ai, req, parseAnswer, 1)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if answer != `{"a":1}` {
+        t.Errorf("answer = %q", answer)
+    }
+    if len(ai.Calls) != 2 {
+        t.Fatalf("calls = %d, want 2", len(ai.Calls))
+    }
+    repair := ai.Calls[1].Messages
+    if len(repair) != 3 || repair[1].Content != "sure!" || !strings.Contains(repair[2].Content, "not a JSON object") {
+        t.Errorf("repair messages = %+v", repair)
+    }
+    if len(req.Messages) != 1 {
+        t.Errorf("the request of the caller is changed: %+v", req.Messages)
+    }
+}
+
+func TestCompleteJSONGivesUp(t *testing.T) {
+    ai := NewFake("fake", "no", "still no")
+    _, err := CompleteJSON(context.Background(), ai, Request{}, parseAnswer, 1)
+    if err == nil || !strings.Contains(err.Error(), "still no") {
+        t.Errorf("err = %v, want the last answer quoted", err)
+    }
+}
+
+func TestCachedJSON(t *testing.T) {
+    store := cache.NewStore(t.TempDir())
+    c := Cache{Store: store, Kind: cache.KindTranslation, Key: cache.Key("question")}
+    ai := NewFake("fake", `{"a":1}`)
+    for i := 0; i < 2; i++ {
+        answer, err := CachedJSON(context.Background(), ai, c, Request{}, parseAnswer, 0)
+        if err != nil || answer != `{"a":1}` {
+            t.Fatalf("answer = %q, err = %v", answer, err)
+        }
+    }
+    if len(ai.Calls) != 1 {
+        t.Errorf("calls = %d, want the second answer from the cache", len(ai.Calls))
+    }
+
+    c.Key, c.Offline = cache.Key("another question"), true
+    if _, err := CachedJSON(context.Background(), ai, c, Request{}, parseAnswer, 0); !errors.Is(err, cache.ErrMiss) {
+        t.Errorf("err = %v, want a cache miss in offline mode", err)
+    }
+}
@@ -0,0 +1,108 @@
+package llm
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "io"
+    "net/http"
+    "strings"
+)
+
+const defaultBaseURL = "https://api.openai.com/v1"
+
+// Embedder turns texts into vectors, similar texts get close vectors.
+type Embedder interface {
+    Embed(ctx context.Context, texts []string) ([][]float32, error)
+    Model() string
+}
+
+func NewEmbedder(llmCfg *config.LLMConfig, cfg *config.EmbeddingsConfig, apiKey string) (Embedder, error) {
+    switch cfg.Provider {
+    case config.ProviderOpenAI:
+        return NewOpenAIEmbedder(llmCfg, cfg.Model, apiKey), nil
+    case config.ProviderHash:
+        return NewHashEmbedder(cfg.Dimensions), nil
+    default:
+        return nil, fmt.Errorf("unknown embeddings provider `%s`", cfg.Provider)
+    }
+}
+
+// OpenAIEmbedder calls the `/embeddings` endpoint of the OpenAI API or a compatible server. The openai client
+// accepts only the known OpenAI models, so the request is made directly.
+type OpenAIEmbedder struct {
+    client  *http.Client
+    baseURL string
+    apiKey  string
+    model   string
+}
+
+func NewOpenAIEmbedder(llmCfg *config.LLMConfig, model string, apiKey string) *OpenAIEmbedder {
+    baseURL := llmCfg.BaseURL
+    if baseURL == "" {
+        baseURL = defaultBaseURL
+    }
+    return &OpenAIEmbedder{
+        client:  &http.Client{Timeout: llmCfg.Timeout},
+        baseURL: strings.TrimRight(baseURL, "/"),
+        apiKey:  apiKey,
+        model:   model,
+    }
+}
+
+func (e *OpenAIEmbedder) Model() string {
+    return e.model
+}
+
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+    body, err := json.Marshal(map[string]interface{}{
+        "model": e.model,
+        "input": texts,
+    })
+    if err != nil {
+        return nil, fmt.Errorf("failed to encode embeddings request: %w", err)
+    }
+    req, err := http.NewRequestWithContext(ctx, http.MethodPost, e.baseURL+"/embeddings", bytes.NewReader(body))
+    if err != nil {
+        return nil, fmt.Errorf("failed to create embeddings request: %w", err)
+    }
+    req.Header.Set("Content-Type", "application/json")
+    if e.apiKey != "" {
+        req.Header.Set("Authorization", "Bearer "+e.apiKey)
+    }
+    resp, err := e.client.Do(req)
+    if err != nil {
+        return nil, fmt.Errorf("failed to request embeddings: %w", err)
+    }
+    defer resp.Body.Close()
+    bt, err := io.ReadAll(resp.Body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to read embeddings response: %w", err)
+    }
+    if resp.StatusCode != http.StatusOK {
+        return nil, fmt.Errorf("embeddings request failed with status %d: %s", resp.StatusCode, bt)
+    }
+    var parsed struct {
+        Data []struct {
+            Embedding []float32 `json:"embedding"`
+            Index     int       `json:"index"`
+        } `json:"data"`
+    }
+    err = json.Unmarshal(bt, &parsed)
+    if err != nil {
+        return nil, fmt.Errorf("failed to decode embeddings response: %w", err)
+    }
+    if len(parsed.Data) != len(texts) {
+        return nil, fmt.Errorf("expected %d embeddings, got %d", len(texts), len(parsed.Data))
+    }
+    vectors := make([][]float32, len(texts))
+    for _, d := range parsed.Data {
+        if d.Index < 0 || d.Index >= len(texts) {
+            return nil, fmt.Errorf("unexpected embedding index %d", d.Index)
+        }
+        vectors[d.Index] = d.Embedding
+    }
+    return vectors, nil
+}
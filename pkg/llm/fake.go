@@ -0,0 +1,48 @@
+package llm
+
+import (
+    "context"
+    "fmt"
+    "sync"
+)
+
+// Fake is a deterministic LLM for tests. It returns the configured responses in order and records the requests.
+type Fake struct {
+    Responses []string
+    Calls     []Request
+    model     string
+    mu        sync.Mutex
+}
+
+func NewFake(model string, responses ...string) *Fake {
+    return &Fake{
+        Responses: responses,
+        model:     model,
+    }
+}
+
+func (f *Fake) Model() string {
+    return f.model
+}
+
+func (f *Fake) Complete(_ context.Context, req Request) (*Response, error) {
+    f.mu.Lock()
+    defer f.mu.Unlock()
+    if len(f.Calls) >= len(f.Responses) {
+        return nil, fmt.Errorf("fake llm has no response for call #%d", len(f.Calls)+1)
+    }
+    content := f.Responses[len(f.Calls)]
+    f.Calls = append(f.Calls, req)
+    promptTokens := 0
+    for _, m := range req.Messages {
+        promptTokens += len(m.Content) / 4
+    }
+    return &Response{
+        Content: content,
+        Usage: Usage{
+            PromptTokens:     promptTokens,
+            CompletionTokens: len(content) / 4,
+            TotalTokens:      promptTokens + len(content)/4,
+        },
+    }, nil
+}
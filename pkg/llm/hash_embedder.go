@@ -0,0 +1,70 @@
+package llm
+
+import (
+    "context"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/tokens"
+    "hash/fnv"
+    "math"
+)
+
+const defaultHashDimensions = 256
+
+// HashEmbedder is a local deterministic stand-in for embedding models: it hashes the words and their pairs
+// into a fixed number of dimensions. It works offline and in tests, but captures only lexical similarity.
+type HashEmbedder struct {
+    dimensions int
+}
+
+func NewHashEmbedder(dimensions int) *HashEmbedder {
+    if dimensions <= 0 {
+        dimensions = defaultHashDimensions
+    }
+    return &HashEmbedder{dimensions: dimensions}
+}
+
+func (e *HashEmbedder) Model() string {
+    return fmt.Sprintf("hash-%d", e.dimensions)
+}
+
+func (e *HashEmbedder) Embed(_ context.Context, texts []string) ([][]float32, error) {
+    vectors := make([][]float32, len(texts))
+    for i, text := range texts {
+        vectors[i] = e.embed(text)
+    }
+    return vectors, nil
+}
+
+func (e *HashEmbedder) embed(text string) []float32 {
+    vec := make([]float32, e.dimensions)
+    words := tokens.Words(text)
+    for i, w := range words {
+        e.add(vec, w, 1)
+        if i > 0 {
+            e.add(vec, words[i-1]+" "+w, 0.5)
+        }
+    }
+    norm := 0.0
+    for _, v := range vec {
+        norm += float64(v * v)
+    }
+    if norm == 0 {
+        return vec
+    }
+    norm = math.Sqrt(norm)
+    for i := range vec {
+        vec[i] = float32(float64(vec[i]) / norm)
+    }
+    return vec
+}
+
+func (e *HashEmbedder) add(vec []float32, feature string, weight float32) {
+    h := fnv.New32a()
+    _, _ = h.Write([]byte(feature))
+    sum := h.Sum32()
+    // the highest bit chooses the sign, so collisions cancel out instead of piling up
+    if sum&(1<<31) != 0 {
+        weight = -weight
+    }
+    vec[int(sum%uint32(e.dimensions))] += weight
+}
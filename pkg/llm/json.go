@@ -0,0 +1,58 @@
+package llm
+
+import (
+    "errors"
+    "strings"
+)
+
+// ExtractJSON finds the JSON object in an answer that may contain a preamble, code fences or trailing text.
+func ExtractJSON(content string) (string, error) {
+    content = strings.TrimSpace(content)
+    if fenced, ok := fencedBlock(content); ok {
+        content = fenced
+    }
+    start := strings.Index(content, "{")
+    if start < 0 {
+        return "", errors.New("response does not contain a JSON object")
+    }
+    depth := 0
+    inString := false
+    escaped := false
+    for i := start; i < len(content); i++ {
+        ch := content[i]
+        switch {
+        case escaped:
+            escaped = false
+        case inString && ch == '\\':
+            escaped = true
+        case ch == '"':
+            inString = !inString
+        case inString:
+        case ch == '{':
+            depth++
+        case ch == '}':
+            depth--
+            if depth == 0 {
+                return content[start : i+1], nil
+            }
+        }
+    }
+    return "", errors.New("response contains an unterminated JSON object")
+}
+
+func fencedBlock(content string) (string, bool) {
+    start := strings.Index(content, "```")
+    if start < 0 {
+        return "", false
+    }
+    rest := content[start+3:]
+    // skip the language tag, e.g. ```json
+    if nl := strings.Index(rest, "\n"); nl >= 0 && !strings.Contains(rest[:nl], "{") {
+        rest = rest[nl+1:]
+    }
+    end := strings.Index(rest, "```")
+    if end < 0 {
+        return "", false
+    }
+    return rest[:end], true
+}
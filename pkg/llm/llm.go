@@ -0,0 +1,50 @@
+package llm
+
+import (
+    "context"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+)
+
+const (
+    RoleSystem    = "system"
+    RoleUser      = "user"
+    RoleAssistant = "assistant"
+)
+
+type Message struct {
+    Role    string
+    Content string
+}
+
+type Request struct {
+    Messages []Message
+    // JSON asks the provider to constrain the answer to a JSON object, if it supports that
+    JSON bool
+}
+
+type Usage struct {
+    PromptTokens     int
+    CompletionTokens int
+    TotalTokens      int
+}
+
+type Response struct {
+    Content string
+    Usage   Usage
+}
+
+// LLM is a chat model provider.
+type LLM interface {
+    Complete(ctx context.Context, req Request) (*Response, error)
+    Model() string
+}
+
+func New(cfg *config.LLMConfig, apiKey string) (LLM, error) {
+    switch cfg.Provider {
+    case config.ProviderOpenAI:
+        return NewOpenAI(cfg, apiKey), nil
+    default:
+        return nil, fmt.Errorf("unknown llm provider `%s`", cfg.Provider)
+    }
+}
@@ -0,0 +1,50 @@
+package llm
+
+import (
+    "context"
+    "sync"
+)
+
+// Meter wraps a model and counts the calls and the tokens spent.
+type Meter struct {
+    llm   LLM
+    calls int
+    usage Usage
+    mu    sync.Mutex
+}
+
+func NewMeter(llm LLM) *Meter {
+    return &Meter{llm: llm}
+}
+
+func (m *Meter) Model() string {
+    return m.llm.Model()
+}
+
+func (m *Meter) Complete(ctx context.Context, req Request) (*Response, error) {
+    resp, err := m.llm.Complete(ctx, req)
+    if err != nil {
+        return nil, err
+    }
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    m.calls++
+    m.usage.PromptTokens += resp.Usage.PromptTokens
+    m.usage.CompletionTokens += resp.Usage.CompletionTokens
+    m.usage.TotalTokens += resp.Usage.TotalTokens
+    return resp, nil
+}
+
+// Calls returns the number of successful completions.
+func (m *Meter) Calls() int {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.calls
+}
+
+// Usage returns the tokens spent by all completions.
+func (m *Meter) Usage() Usage {
+    m.mu.Lock()
+    defer m.mu.Unlock()
+    return m.usage
+}
@@ -0,0 +1,105 @@
+package llm
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/sashabaranov/go-openai"
+    "io"
+    "net/http"
+)
+
+// OpenAI works with the OpenAI API and any server compatible with it (Ollama, llama.cpp, vLLM, etc.).
+type OpenAI struct {
+    client      *openai.Client
+    model       string
+    temperature float32
+    jsonMode    bool
+}
+
+func NewOpenAI(cfg *config.LLMConfig, apiKey string) *OpenAI {
+    clientCfg := openai.DefaultConfig(apiKey)
+    if cfg.BaseURL != "" {
+        clientCfg.BaseURL = cfg.BaseURL
+    }
+    clientCfg.HTTPClient = &http.Client{
+        Timeout:   cfg.Timeout,
+        Transport: &responseFormatTransport{base: http.DefaultTransport},
+    }
+    return &OpenAI{
+        client:      openai.NewClientWithConfig(clientCfg),
+        model:       cfg.Model,
+        temperature: cfg.Temperature,
+        jsonMode:    cfg.JSONMode != nil && *cfg.JSONMode,
+    }
+}
+
+func (o *OpenAI) Model() string {
+    return o.model
+}
+
+func (o *OpenAI) Complete(ctx context.Context, req Request) (*Response, error) {
+    oreq := openai.ChatCompletionRequest{
+        Model:       o.model,
+        Temperature: o.temperature,
+    }
+    for _, m := range req.Messages {
+        oreq.Messages = append(oreq.Messages, openai.ChatCompletionMessage{
+            Role:    m.Role,
+            Content: m.Content,
+        })
+    }
+    if req.JSON && o.jsonMode {
+        ctx = context.WithValue(ctx, jsonObjectFormatKey{}, true)
+    }
+    resp, err := o.client.CreateChatCompletion(ctx, oreq)
+    if err != nil {
+        return nil, fmt.Errorf("failed to create chat completion: %w", err)
+    }
+    if len(resp.Choices) == 0 {
+        return nil, fmt.Errorf("chat completion has no choices")
+    }
+    return &Response{
+        Content: resp.Choices[0].Message.Content,
+        Usage: Usage{
+            PromptTokens:     resp.Usage.PromptTokens,
+            CompletionTokens: resp.Usage.CompletionTokens,
+            TotalTokens:      resp.Usage.TotalTokens,
+        },
+    }, nil
+}
+
+type jsonObjectFormatKey struct{}
+
+// responseFormatTransport adds `response_format` to requests marked with jsonObjectFormatKey,
+// because the openai client doesn't support JSON mode yet.
+type responseFormatTransport struct {
+    base http.RoundTripper
+}
+
+func (t *responseFormatTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+    if r.Body == nil || r.Context().Value(jsonObjectFormatKey{}) == nil {
+        return t.base.RoundTrip(r)
+    }
+    bt, err := io.ReadAll(r.Body)
+    _ = r.Body.Close()
+    if err != nil {
+        return nil, fmt.Errorf("failed to read request body: %w", err)
+    }
+    body := map[string]json.RawMessage{}
+    err = json.Unmarshal(bt, &body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to decode request body: %w", err)
+    }
+    body["response_format"] = json.RawMessage(`{"type":"json_object"}`)
+    bt, err = json.Marshal(body)
+    if err != nil {
+        return nil, fmt.Errorf("failed to encode request body: %w", err)
+    }
+    r = r.Clone(r.Context())
+    r.Body = io.NopCloser(bytes.NewReader(bt))
+    r.ContentLength = int64(len(bt))
+    return t.base.RoundTrip(r)
+}
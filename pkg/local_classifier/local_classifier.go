@@ -0,0 +1,359 @@
+package local_classifier
+
+import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
+    "errors"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/readme_preprocessor"
+    "github.com/korchasa/awesome-toolkit/pkg/tokens"
+    log "github.com/sirupsen/logrus"
+    "math"
+    "sort"
+    "strings"
+)
+
+const (
+    ModelName = "local-naive-bayes"
+
+    // readmeTokenLimit is the estimated tokens budget for the readme part of the document
+    readmeTokenLimit = 1000
+    // smoothing is the additive (Laplace) smoothing of term weights
+    smoothing = 0.1
+    // calibrationFolds is the number of cross-validation folds used to calibrate confidences
+    calibrationFolds = 5
+    // minCalibrationItems is the smallest training set to calibrate on, smaller sets use the raw posteriors
+    minCalibrationItems = 20
+    // rationaleTerms is the number of the most indicative terms quoted as the suggestion rationale
+    rationaleTerms = 5
+)
+
+var ErrNotEnoughData = errors.New("at least two categories with curated items are needed")
+
+type Prediction struct {
+    Category   string
+    Confidence float64
+}
+
+// LocalClassifier is a TF-IDF weighted multinomial naive Bayes classifier trained on the curated items.
+// It works without any network access and is used for cheap pre-screening before the LLM.
+type LocalClassifier struct {
+    model       *model
+    temperature float64
+    version     string
+    suggestions int
+}
+
+type doc struct {
+    category string
+    terms    map[string]float64
+}
+
+type model struct {
+    idf        map[string]float64
+    categories []string
+    logPrior   map[string]float64
+    logLikely  map[string]map[string]float64
+    logUnknown map[string]float64
+}
+
+// Train builds the classifier from the non-ignored categorized items. The readmeFor function returns
+// a cached readme of the item, or an empty string.
+func Train(items []*list.Item, readmeFor func(*list.Item) string) (*LocalClassifier, error) {
+    var texts []string
+    var categories []string
+    h := sha256.New()
+    for _, item := range items {
+        if item.Ignore || item.Pending || item.Category == "" {
+            continue
+        }
+        readme := ""
+        if readmeFor != nil {
+            readme = readmeFor(item)
+        }
+        texts = append(texts, documentText(item, readme))
+        categories = append(categories, item.Category)
+        h.Write([]byte(item.Link + "\n" + item.Category + "\n"))
+    }
+    docs := vectorize(texts, categories)
+    m, err := fit(docs)
+    if err != nil {
+        return nil, err
+    }
+    c := &LocalClassifier{
+        model:       m,
+        temperature: calibrate(docs),
+        version:     hex.EncodeToString(h.Sum(nil))[:12],
+        suggestions: 1,
+    }
+    log.Infof("Local classifier trained on %d items in %d categories", len(docs), len(m.categories))
+    return c, nil
+}
+
+// WithSuggestions makes the classifier propose up to n ranked categories for review
+func (c *LocalClassifier) WithSuggestions(n int) *LocalClassifier {
+    if n > 0 {
+        c.suggestions = n
+    }
+    return c
+}
+
+func (c *LocalClassifier) Model() string {
+    return ModelName
+}
+
+// PromptVersion identifies the training set, so decisions of differently trained models can be told apart.
+func (c *LocalClassifier) PromptVersion() string {
+    return c.version
+}
+
+func (c *LocalClassifier) ClassifyRepo(_ context.Context, item *list.Item, readme string) error {
+    terms := c.model.weigh(termCounts(documentText(item, readme)))
+    predictions := softmax(c.model.scores(terms), c.temperature)
+    item.AICategory = predictions[0].Category
+    item.AICategoryConfidence = float32(predictions[0].Confidence)
+    item.AISuggestions = nil
+    for i, p := range predictions {
+        if i == c.suggestions {
+            break
+        }
+        item.AISuggestions = append(item.AISuggestions, list.Suggestion{
+            Category:   p.Category,
+            Confidence: float32(p.Confidence),
+            Rationale:  rationale(c.model.topTerms(terms, p.Category, rationaleTerms)),
+        })
+    }
+    item.AIModel = c.Model()
+    item.AIPromptVersion = c.PromptVersion()
+    return nil
+}
+
+func rationale(terms []string) string {
+    if len(terms) == 0 {
+        return ""
+    }
+    return "indicative terms: " + strings.Join(terms, ", ")
+}
+
+// Predict returns all categories ordered by calibrated confidence.
+func (c *LocalClassifier) Predict(text string) []Prediction {
+    terms := c.model.weigh(termCounts(text))
+    return softmax(c.model.scores(terms), c.temperature)
+}
+
+func documentText(item *list.Item, readme string) string {
+    return strings.Join([]string{
+        strings.ReplaceAll(item.Name, "/", " "),
+        item.Description,
+        item.AIDescription,
+        readme_preprocessor.Preprocess(readme, readmeTokenLimit),
+    }, "\n")
+}
+
+func termCounts(text string) map[string]float64 {
+    counts := map[string]float64{}
+    for _, t := range tokens.Terms(text) {
+        counts[t]++
+    }
+    return counts
+}
+
+// vectorize turns texts into term counts, the TF-IDF weighting is applied by the model
+func vectorize(texts []string, categories []string) []doc {
+    docs := make([]doc, len(texts))
+    for i, text := range texts {
+        docs[i] = doc{category: categories[i], terms: termCounts(text)}
+    }
+    return docs
+}
+
+func fit(docs []doc) (*model, error) {
+    m := &model{
+        idf:        map[string]float64{},
+        logPrior:   map[string]float64{},
+        logLikely:  map[string]map[string]float64{},
+        logUnknown: map[string]float64{},
+    }
+    df := map[string]int{}
+    docsPerCategory := map[string]int{}
+    for _, d := range docs {
+        for t := range d.terms {
+            df[t]++
+        }
+        docsPerCategory[d.category]++
+    }
+    if len(docsPerCategory) < 2 {
+        return nil, ErrNotEnoughData
+    }
+    for t, n := range df {
+        m.idf[t] = math.Log(float64(len(docs)+1)/float64(n+1)) + 1
+    }
+    weights := map[string]map[string]float64{}
+    totals := map[string]float64{}
+    for _, d := range docs {
+        if weights[d.category] == nil {
+            weights[d.category] = map[string]float64{}
+        }
+        for t, w := range m.weigh(d.terms) {
+            weights[d.category][t] += w
+            totals[d.category] += w
+        }
+    }
+    vocabulary := float64(len(m.idf))
+    for cat, n := range docsPerCategory {
+        m.categories = append(m.categories, cat)
+        m.logPrior[cat] = math.Log(float64(n) / float64(len(docs)))
+        denominator := totals[cat] + smoothing*vocabulary
+        m.logLikely[cat] = map[string]float64{}
+        for t, w := range weights[cat] {
+            m.logLikely[cat][t] = math.Log((w + smoothing) / denominator)
+        }
+        m.logUnknown[cat] = math.Log(smoothing / denominator)
+    }
+    sort.Strings(m.categories)
+    return m, nil
+}
+
+// weigh applies sublinear TF-IDF weighting and L2 normalization, unknown terms are dropped
+func (m *model) weigh(counts map[string]float64) map[string]float64 {
+    weighted := map[string]float64{}
+    norm := 0.0
+    for t, n := range counts {
+        idf, ok := m.idf[t]
+        if !ok {
+            continue
+        }
+        w := (1 + math.Log(n)) * idf
+        weighted[t] = w
+        norm += w * w
+    }
+    if norm == 0 {
+        return weighted
+    }
+    norm = math.Sqrt(norm)
+    for t := range weighted {
+        weighted[t] /= norm
+    }
+    return weighted
+}
+
+func (m *model) scores(terms map[string]float64) map[string]float64 {
+    scores := map[string]float64{}
+    for _, cat := range m.categories {
+        score := m.logPrior[cat]
+        for t, w := range terms {
+            if l, ok := m.logLikely[cat][t]; ok {
+                score += w * l
+            } else {
+                score += w * m.logUnknown[cat]
+            }
+        }
+        scores[cat] = score
+    }
+    return scores
+}
+
+// topTerms returns the terms of the document that speak for the category the most
+func (m *model) topTerms(terms map[string]float64, category string, n int) []string {
+    type contribution struct {
+        term  string
+        value float64
+    }
+    var contributions []contribution
+    for t, w := range terms {
+        l, ok := m.logLikely[category][t]
+        if !ok {
+            continue
+        }
+        contributions = append(contributions, contribution{term: t, value: w * (l - m.logUnknown[category])})
+    }
+    sort.Slice(contributions, func(i, j int) bool {
+        if contributions[i].value != contributions[j].value {
+            return contributions[i].value > contributions[j].value
+        }
+        return contributions[i].term < contributions[j].term
+    })
+    var top []string
+    for i, c := range contributions {
+        if i == n {
+            break
+        }
+        top = append(top, c.term)
+    }
+    return top
+}
+
+func softmax(scores map[string]float64, temperature float64) []Prediction {
+    maxScore := math.Inf(-1)
+    for _, s := range scores {
+        maxScore = math.Max(maxScore, s)
+    }
+    sum := 0.0
+    predictions := make([]Prediction, 0, len(scores))
+    for cat, s := range scores {
+        p := math.Exp((s - maxScore) / temperature)
+        sum += p
+        predictions = append(predictions, Prediction{Category: cat, Confidence: p})
+    }
+    for i := range predictions {
+        predictions[i].Confidence /= sum
+    }
+    sort.Slice(predictions, func(i, j int) bool {
+        if predictions[i].Confidence != predictions[j].Confidence {
+            return predictions[i].Confidence > predictions[j].Confidence
+        }
+        return predictions[i].Category < predictions[j].Category
+    })
+    return predictions
+}
+
+// calibrate finds the softmax temperature that minimizes the negative log-likelihood of the
+// cross-validated predictions, so the confidence is close to the observed accuracy.
+func calibrate(docs []doc) float64 {
+    if len(docs) < minCalibrationItems {
+        return 1
+    }
+    type heldOut struct {
+        scores   map[string]float64
+        category string
+    }
+    var predictions []heldOut
+    for fold := 0; fold < calibrationFolds; fold++ {
+        var train, test []doc
+        for i, d := range docs {
+            if i%calibrationFolds == fold {
+                test = append(test, d)
+            } else {
+                train = append(train, d)
+            }
+        }
+        m, err := fit(train)
+        if err != nil {
+            continue
+        }
+        for _, d := range test {
+            predictions = append(predictions, heldOut{scores: m.scores(m.weigh(d.terms)), category: d.category})
+        }
+    }
+    if len(predictions) == 0 {
+        return 1
+    }
+    best, bestLoss := 1.0, math.Inf(1)
+    for t := 0.01; t <= 10; t *= 1.25 {
+        loss := 0.0
+        for _, p := range predictions {
+            confidence := 1e-6
+            for _, pred := range softmax(p.scores, t) {
+                if pred.Category == p.category {
+                    confidence = math.Max(pred.Confidence, 1e-6)
+                }
+            }
+            loss -= math.Log(confidence)
+        }
+        if loss < bestLoss {
+            best, bestLoss = t, loss
+        }
+    }
+    return best
+}
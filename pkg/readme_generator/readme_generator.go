@@ -9,81 +9,203 @@ import (
     "strings"
 )
 
-const BodyPlaceholder = "{{body}}"
+const (
+    BodyPlaceholder      = "{{body}}"
+    LanguagesPlaceholder = "{{languages}}"
+)
+
+// maturityColors are the shields.io colors of the maturity badges
+var maturityColors = map[string]string{
+    "experimental": "orange",
+    "beta":         "yellow",
+    "production":   "brightgreen",
+}
+
+// localeNames are the names of the common locales in the language itself, used for the cross-links
+var localeNames = map[string]string{
+    "de": "Deutsch",
+    "en": "English",
+    "es": "Español",
+    "fr": "Français",
+    "it": "Italiano",
+    "ja": "日本語",
+    "ko": "한국어",
+    "pt": "Português",
+    "ru": "Русский",
+    "uk": "Українська",
+    "zh": "中文",
+}
 
 type ReadmeGenerator struct {
 }
 
+// view is what the readme of one locale is rendered with
+type view struct {
+    locale     string
+    translated bool
+    precedence []string
+    badges     bool
+}
+
 func NewReadmeGenerator() *ReadmeGenerator {
     return &ReadmeGenerator{}
 }
 
-func (r *ReadmeGenerator) Generate(cfg *config.Config, list *list.List) (content string, err error) {
-    tpl, err := os.ReadFile(cfg.ReadmeTemplatePath())
+// Generate renders the readme of the locale, the main one or one of the translations
+func (r *ReadmeGenerator) Generate(cfg *config.Config, list *list.List, locale string) (content string, err error) {
+    tplPath := cfg.ReadmeTemplatePathFor(locale)
+    tpl, err := os.ReadFile(tplPath)
     if err != nil {
-        return "", fmt.Errorf("failed to read template `%s`: %w", cfg.ReadmeTemplatePath(), err)
+        return "", fmt.Errorf("failed to read template `%s`: %w", tplPath, err)
     }
     content = string(tpl)
     if !strings.Contains(content, BodyPlaceholder) {
-        return "", fmt.Errorf("template `%s` does not contain `%s`", cfg.ReadmeTemplatePath(), BodyPlaceholder)
+        return "", fmt.Errorf("template `%s` does not contain `%s`", tplPath, BodyPlaceholder)
     }
 
     sort.Slice(list.Items, func(i, j int) bool {
         return list.Items[i].Name < list.Items[j].Name
     })
 
+    v := view{
+        locale:     locale,
+        translated: locale != cfg.Readme.Locale,
+        precedence: cfg.Readme.DescriptionPrecedence,
+        badges:     badgesEnabled(cfg),
+    }
     tocBody := ""
     itemsBody := ""
     for _, category := range cfg.Root.Categories {
-        tocBody += genSublistTOC(category, list, 1)
-        itemsBody += genSublist(category, list, 1)
+        tocBody += genSublistTOC(category, list, 1, v)
+        itemsBody += genSublist(category, list, 1, v)
     }
 
+    languages := genLanguages(cfg, locale)
+    if strings.Contains(content, LanguagesPlaceholder) {
+        content = strings.Replace(content, LanguagesPlaceholder, languages, 1)
+    } else if languages != "" {
+        tocBody = languages + "\n\n" + tocBody
+    }
     content = strings.Replace(content, BodyPlaceholder, tocBody+itemsBody, 1)
     return content, nil
 }
 
-func genSublistTOC(category *config.CategoryDescription, l *list.List, i int) string {
+// genLanguages renders the links to the readmes of the other locales, empty if the readme isn't translated
+func genLanguages(cfg *config.Config, current string) string {
+    if len(cfg.Readme.Locales) == 0 {
+        return ""
+    }
+    var links []string
+    for _, locale := range append([]string{cfg.Readme.Locale}, cfg.Readme.Locales...) {
+        name := localeName(locale)
+        if locale == current {
+            links = append(links, "**"+name+"**")
+            continue
+        }
+        filename := config.ReadmeFilename
+        if locale != cfg.Readme.Locale {
+            filename = config.LocalReadmeFilename(locale)
+        }
+        links = append(links, fmt.Sprintf("[%s](%s)", name, filename))
+    }
+    return strings.Join(links, " | ")
+}
+
+func localeName(locale string) string {
+    if name, ok := localeNames[locale]; ok {
+        return name
+    }
+    return locale
+}
+
+func genSublistTOC(category *config.CategoryDescription, l *list.List, i int, v view) string {
 
     content := fmt.Sprintf(
         "%s- [%s](#%s)\n",
         strings.Repeat("  ", i),
-        category.Title,
-        anchorFor(category.Title))
+        category.LocalTitle(v.locale),
+        anchorFor(category.LocalTitle(v.locale)))
     for _, sc := range category.Categories {
-        content += genSublistTOC(sc, l, i+1)
+        content += genSublistTOC(sc, l, i+1, v)
     }
     return content
 }
 
-func genSublist(cat *config.CategoryDescription, list *list.List, intend int) string {
-    content := fmt.Sprintf("%s %s\n\n", strings.Repeat("#", intend+1), cat.Title)
+func genSublist(cat *config.CategoryDescription, list *list.List, intend int, v view) string {
+    content := fmt.Sprintf("%s %s\n\n", strings.Repeat("#", intend+1), cat.LocalTitle(v.locale))
     for _, item := range list.Items {
         if item.Ignore || item.Category != cat.Title {
             continue
         }
-        desc := genDesc(item)
-        if desc == "" {
-            content += fmt.Sprintf("- [%s](%s)\n", item.Name, item.Link)
-        } else {
-            content += fmt.Sprintf("- [%s](%s) - %s\n", item.Name, item.Link, desc)
+        line := fmt.Sprintf("- [%s](%s)", item.Name, item.Link)
+        if v.badges && item.AIAssessment != nil {
+            line += " " + genBadges(item.AIAssessment)
         }
+        if desc := genDesc(item, v); desc != "" {
+            line += " - " + desc
+        }
+        content += line + "\n"
     }
     content += "\n"
     for _, sc := range cat.Categories {
-        content += genSublist(sc, list, intend+1)
+        content += genSublist(sc, list, intend+1, v)
     }
     return content
 }
 
-func genDesc(item *list.Item) string {
-    desc := item.Description
-    if item.AIDescription != "" {
-        desc = item.AIDescription
+// genDesc renders the description of the item, translated if the readme is and the translation is up to date
+func genDesc(item *list.Item, v view) string {
+    desc := Description(item, v.precedence)
+    if v.translated {
+        if t := item.Translation(v.locale, desc); t != "" {
+            desc = t
+        }
     }
     return replaceMarkdownSymbols(desc)
 }
 
+// Description returns the first non-empty description field in the order of precedence
+func Description(item *list.Item, precedence []string) string {
+    for _, field := range precedence {
+        var desc string
+        switch field {
+        case config.DescriptionCurator:
+            desc = item.CuratorDescription
+        case config.DescriptionAI:
+            desc = item.AIDescription
+        case config.DescriptionRepo:
+            desc = item.Description
+        }
+        if desc != "" {
+            return desc
+        }
+    }
+    return ""
+}
+
+func badgesEnabled(cfg *config.Config) bool {
+    return cfg.Assessment != nil && cfg.Assessment.Badges
+}
+
+// genBadges renders the kind and maturity of the item as shields.io badges
+func genBadges(a *list.Assessment) string {
+    color, ok := maturityColors[a.Maturity]
+    if !ok {
+        color = "lightgrey"
+    }
+    return fmt.Sprintf(
+        "![%s](https://img.shields.io/badge/kind-%s-blue) ![%s](https://img.shields.io/badge/maturity-%s-%s)",
+        a.Kind, badgeText(a.Kind), a.Maturity, badgeText(a.Maturity), color,
+    )
+}
+
+// badgeText escapes the text for the shields.io static badge path
+func badgeText(s string) string {
+    s = strings.ReplaceAll(s, "-", "--")
+    s = strings.ReplaceAll(s, "_", "__")
+    return strings.ReplaceAll(s, " ", "_")
+}
+
 func anchorFor(s string) string {
     s = strings.ToLower(s)
     s = strings.ReplaceAll(s, "/", "")
@@ -0,0 +1,212 @@
+package readme_preprocessor
+
+import (
+    "regexp"
+    "strings"
+)
+
+const (
+    FormatMarkdown = "markdown"
+    FormatRST      = "rst"
+    FormatAsciiDoc = "asciidoc"
+    FormatOrg      = "org"
+)
+
+const rstAdornmentChars = "=-~^\"'*+#:.`"
+
+var (
+    rstDirectiveRe   = regexp.MustCompile(`^\.\.\s+(\|[^|]+\|\s+)?([\w-]+)::`)
+    rstCommentRe     = regexp.MustCompile(`^\.\.(\s|$)`)
+    rstLinkRe        = regexp.MustCompile("`([^`<]+?)\\s*<[^>]+>`__?")
+    rstRefRe         = regexp.MustCompile("`([^`]+)`_")
+    rstSubstRe       = regexp.MustCompile(`\|[^|\s][^|]*\|_?`)
+    rstLiteralRe     = regexp.MustCompile("``([^`]+)``")
+    adocHeadingRe    = regexp.MustCompile(`^(={1,6})\s+(.*)$`)
+    adocAttributeRe  = regexp.MustCompile(`^:[\w-]+:.*$`)
+    adocImageRe      = regexp.MustCompile(`image::?[^\[\s]*\[[^\]]*\]`)
+    adocLinkRe       = regexp.MustCompile(`(?:link:)?(?:https?://|mailto:)?[^\s\[]*\[([^\]]*)\]`)
+    adocBlockAttrRe  = regexp.MustCompile(`^\[[^\]]*\]$`)
+    orgHeadingRe     = regexp.MustCompile(`^(\*{1,6})\s+(.*)$`)
+    orgTitleRe       = regexp.MustCompile(`(?i)^#\+title:\s*(.*)$`)
+    orgBeginSrcRe    = regexp.MustCompile(`(?i)^#\+begin_(src|example)`)
+    orgEndSrcRe      = regexp.MustCompile(`(?i)^#\+end_(src|example)`)
+    orgKeywordRe     = regexp.MustCompile(`^#\+\w+.*$`)
+    orgLinkRe        = regexp.MustCompile(`\[\[[^\]]*\]\[([^\]]*)\]\]`)
+    orgBareLinkRe    = regexp.MustCompile(`\[\[([^\]]*)\]\]`)
+    orgImageLinkRe   = regexp.MustCompile(`(?i)\[\[[^\]]*\.(png|jpe?g|gif|svg)\]\]`)
+    markdownHeadRe   = regexp.MustCompile(`(?m)^#{1,6}\s+\S`)
+    markdownLinkRe   = regexp.MustCompile(`\]\([^)]*\)`)
+    asciiDocSignalRe = regexp.MustCompile(`(?m)^(=\s+\S|==\s+\S|:toc:|\[source|----$|image::)`)
+    orgSignalRe      = regexp.MustCompile(`(?mi)^(#\+title:|#\+begin_src|\*+\s+\S.*$)`)
+    rstSignalRe      = regexp.MustCompile(`(?m)^(\.\. [\w|-]+|[=\-~^]{3,}\s*$)`)
+)
+
+// DetectFormat guesses the markup of the readme, since GitHub serves RST, AsciiDoc and Org readmes too.
+func DetectFormat(content string) string {
+    if len(markdownHeadRe.FindAllString(content, -1)) > 0 || len(markdownLinkRe.FindAllString(content, 3)) >= 3 {
+        return FormatMarkdown
+    }
+    if strings.Contains(strings.ToLower(content), "#+title:") || strings.Contains(strings.ToLower(content), "#+begin_src") {
+        return FormatOrg
+    }
+    if len(asciiDocSignalRe.FindAllString(content, -1)) >= 2 {
+        return FormatAsciiDoc
+    }
+    if len(rstSignalRe.FindAllString(content, -1)) >= 2 {
+        return FormatRST
+    }
+    if len(orgSignalRe.FindAllString(content, -1)) >= 2 {
+        return FormatOrg
+    }
+    return FormatMarkdown
+}
+
+func rstToMarkdown(s string) string {
+    lines := strings.Split(s, "\n")
+    var out []string
+    levels := map[byte]int{}
+    skipIndented := false
+    inCode := false
+    for i := 0; i < len(lines); i++ {
+        line := lines[i]
+        indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+        if (skipIndented || inCode) && (indented || strings.TrimSpace(line) == "") {
+            if inCode {
+                out = append(out, strings.TrimLeft(line, " \t"))
+            }
+            continue
+        }
+        if inCode {
+            out = append(out, "```")
+        }
+        skipIndented, inCode = false, false
+
+        // overlined title: the line is repeated above and below the text
+        if isRSTAdornment(line) && i+2 < len(lines) && lines[i+2] == line {
+            out = append(out, rstHeading(levels, line[0], lines[i+1]))
+            i += 2
+            continue
+        }
+        if i+1 < len(lines) && strings.TrimSpace(line) != "" && isRSTAdornment(lines[i+1]) &&
+            len(strings.TrimSpace(lines[i+1])) >= len(strings.TrimSpace(line)) {
+            out = append(out, rstHeading(levels, lines[i+1][0], line))
+            i++
+            continue
+        }
+        if m := rstDirectiveRe.FindStringSubmatch(line); m != nil {
+            switch m[2] {
+            case "code", "code-block", "sourcecode":
+                out = append(out, "```")
+                inCode = true
+            default:
+                skipIndented = true
+            }
+            continue
+        }
+        if rstCommentRe.MatchString(line) {
+            skipIndented = true
+            continue
+        }
+        line = rstLinkRe.ReplaceAllString(line, "$1")
+        line = rstRefRe.ReplaceAllString(line, "$1")
+        line = rstSubstRe.ReplaceAllString(line, "")
+        line = rstLiteralRe.ReplaceAllString(line, "`$1`")
+        if strings.HasSuffix(strings.TrimSpace(line), "::") {
+            out = append(out, strings.TrimSuffix(strings.TrimSpace(line), ":"), "```")
+            inCode = true
+            continue
+        }
+        out = append(out, line)
+    }
+    if inCode {
+        out = append(out, "```")
+    }
+    return strings.Join(out, "\n")
+}
+
+// isRSTAdornment checks that the line is a section over- or underline, e.g. `=====`
+func isRSTAdornment(line string) bool {
+    line = strings.TrimRight(line, " \t")
+    if len(line) < 3 || !strings.ContainsRune(rstAdornmentChars, rune(line[0])) {
+        return false
+    }
+    return strings.Count(line, line[:1]) == len(line)
+}
+
+// rstHeading maps the adornment character to the heading level in the order of appearance, like docutils does
+func rstHeading(levels map[byte]int, adornment byte, title string) string {
+    level, ok := levels[adornment]
+    if !ok {
+        level = len(levels) + 1
+        levels[adornment] = level
+    }
+    if level > 6 {
+        level = 6
+    }
+    return strings.Repeat("#", level) + " " + strings.TrimSpace(title)
+}
+
+func asciiDocToMarkdown(s string) string {
+    var out []string
+    inCode := false
+    for _, line := range strings.Split(s, "\n") {
+        trimmed := strings.TrimSpace(line)
+        if trimmed == "----" || trimmed == "...." {
+            inCode = !inCode
+            out = append(out, "```")
+            continue
+        }
+        if inCode {
+            out = append(out, line)
+            continue
+        }
+        if m := adocHeadingRe.FindStringSubmatch(line); m != nil {
+            out = append(out, strings.Repeat("#", len(m[1]))+" "+m[2])
+            continue
+        }
+        if adocAttributeRe.MatchString(line) || adocBlockAttrRe.MatchString(trimmed) || strings.HasPrefix(trimmed, "//") {
+            continue
+        }
+        line = adocImageRe.ReplaceAllString(line, "")
+        line = adocLinkRe.ReplaceAllString(line, "$1")
+        out = append(out, line)
+    }
+    return strings.Join(out, "\n")
+}
+
+func orgToMarkdown(s string) string {
+    var out []string
+    inCode := false
+    for _, line := range strings.Split(s, "\n") {
+        trimmed := strings.TrimSpace(line)
+        switch {
+        case orgBeginSrcRe.MatchString(trimmed):
+            inCode = true
+            out = append(out, "```")
+            continue
+        case orgEndSrcRe.MatchString(trimmed):
+            inCode = false
+            out = append(out, "```")
+            continue
+        case inCode:
+            out = append(out, line)
+            continue
+        }
+        if m := orgTitleRe.FindStringSubmatch(trimmed); m != nil {
+            out = append(out, "# "+m[1])
+            continue
+        }
+        if orgKeywordRe.MatchString(trimmed) {
+            continue
+        }
+        if m := orgHeadingRe.FindStringSubmatch(line); m != nil {
+            out = append(out, strings.Repeat("#", len(m[1]))+" "+m[2])
+            continue
+        }
+        line = orgImageLinkRe.ReplaceAllString(line, "")
+        line = orgLinkRe.ReplaceAllString(line, "$1")
+        line = orgBareLinkRe.ReplaceAllString(line, "$1")
+        out = append(out, line)
+    }
+    return strings.Join(out, "\n")
+}
@@ -0,0 +1,62 @@
+package readme_preprocessor
+
+import (
+    "testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+    tests := []struct {
+        name   string
+        readme string
+        want   string
+    }{
+        {"markdown", "# Kvs\nA key-value store.", FormatMarkdown},
+        {"plain text", "A key-value store.", FormatMarkdown},
+        {"rst", "Kvs\n===\n\nA key-value store.\n\n.. image:: logo.png\n", FormatRST},
+        {"asciidoc", "= Kvs\n:toc:\n\nA key-value store.\n\n== Usage\n", FormatAsciiDoc},
+        {"org", "#+TITLE: Kvs\n\nA key-value store.\n", FormatOrg},
+        {"org headings", "* Kvs\nA key-value store.\n** Usage\nOpen it.\n", FormatOrg},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := DetectFormat(tt.readme); got != tt.want {
+                t.Errorf("DetectFormat() = %s, want %s", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestConvertMarkup(t *testing.T) {
+    tests := []struct {
+        name   string
+        readme string
+        want   string
+    }{
+        {
+            name: "rst",
+            readme: "===\nKvs\n===\n\n.. image:: logo.png\n   :alt: logo\n\n" +
+                "A `key-value <https://en.wikipedia.org/wiki/Key-value>`_ store |badge|.\n\n" +
+                "Usage\n-----\n\nOpen it with ``kvs.Open``::\n\n    kvs.Open()\n\n.. note:: a comment\n",
+            want: "# Kvs\n\nA key-value store .\n\n## Usage\n\nOpen it with `kvs.Open`:\n```\n\nkvs.Open()\n\n```",
+        },
+        {
+            name: "asciidoc",
+            readme: "= Kvs\n:toc:\n\nimage::logo.png[logo]\nA https://kvs.dev[key-value] store.\n\n" +
+                "== Usage\n[source,go]\n----\nkvs.Open()\n----\n",
+            want: "# Kvs\n\nA key-value store.\n\n## Usage\n```\nkvs.Open()\n```",
+        },
+        {
+            name: "org",
+            readme: "#+TITLE: Kvs\n#+AUTHOR: someone\n\n[[logo.png]]\nA [[https://kvs.dev][key-value]] store.\n\n" +
+                "* Usage\n#+BEGIN_SRC go\nkvs.Open()\n#+END_SRC\n",
+            want: "# Kvs\n\nA key-value store.\n\n# Usage\n```\nkvs.Open()\n```",
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            if got := Preprocess(tt.readme, 1000); got != tt.want {
+                t.Errorf("Preprocess() =\n%q\nwant\n%q", got, tt.want)
+            }
+        })
+    }
+}
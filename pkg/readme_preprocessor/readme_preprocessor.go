@@ -0,0 +1,220 @@
+package readme_preprocessor
+
+import (
+    "regexp"
+    "strings"
+    "unicode"
+    "unicode/utf8"
+)
+
+// maxCodeBlockLines is the longest code block kept in the text, longer ones are mostly install and usage snippets
+const maxCodeBlockLines = 8
+
+var (
+    htmlCommentRe   = regexp.MustCompile(`(?s)<!--.*?-->`)
+    htmlImageRe     = regexp.MustCompile(`(?i)<img[^>]*>`)
+    htmlTagRe       = regexp.MustCompile(`</?[a-zA-Z][^>]*>`)
+    linkedImageRe   = regexp.MustCompile(`\[!\[[^\]]*\]\([^)]*\)\]\([^)]*\)`)
+    imageRe         = regexp.MustCompile(`!\[[^\]]*\]\([^)]*\)`)
+    refImageRe      = regexp.MustCompile(`!?\[!?\[[^\]]*\]\[[^\]]*\]\]\[[^\]]*\]|!\[[^\]]*\]\[[^\]]*\]`)
+    linkRe          = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+    refLinkRe       = regexp.MustCompile(`\[([^\]]+)\]\[[^\]]*\]`)
+    linkDefRe       = regexp.MustCompile(`(?m)^\s*\[[^\]]+\]:\s*\S+.*$`)
+    headingRe       = regexp.MustCompile(`^(#{1,6})\s+(.*)$`)
+    blankLinesRe    = regexp.MustCompile(`\n{3,}`)
+    trailingSpaceRe = regexp.MustCompile(`(?m)[ \t]+$`)
+    codeBlockRe     = regexp.MustCompile("(?s)```.*?```|~~~.*?~~~")
+    inlineCodeRe    = regexp.MustCompile("`[^`\n]*`")
+    urlRe           = regexp.MustCompile(`[a-z]+://\S+`)
+)
+
+// low priority sections rarely say what the project is about, so they are dropped. Titles which may start
+// a describing section too, e.g. "Build pipelines" or "Security scanning", are not listed.
+var lowPrioritySections = []string{
+    "install", "license", "licence", "contribut", "changelog", "change log", "acknowledg", "sponsor", "backer",
+    "donat", "support us", "author", "maintainer", "credit", "thank", "star history", "table of contents", "contents",
+    "badge", "release", "faq", "code of conduct",
+}
+
+// high priority sections describe the project and go right after the intro
+var highPrioritySections = []string{
+    "feature", "overview", "about", "introduction", "description", "what", "why", "highlight", "summary", "motivation",
+    "goal", "key",
+}
+
+// Preprocess turns the readme into plain text suitable for the classification prompt: converts the markup to
+// markdown, strips badges, images, HTML and long code blocks, puts the intro and feature sections first and
+// truncates the result to the token budget.
+func Preprocess(content string, maxTokens int) string {
+    content = strings.ReplaceAll(content, "\r\n", "\n")
+    switch DetectFormat(content) {
+    case FormatRST:
+        content = rstToMarkdown(content)
+    case FormatAsciiDoc:
+        content = asciiDocToMarkdown(content)
+    case FormatOrg:
+        content = orgToMarkdown(content)
+    }
+    content = cleanMarkdown(content)
+    content = prioritizeSections(content)
+    return TruncateTokens(content, maxTokens)
+}
+
+// Prose returns the natural language text of the readme: the preprocessed text without code and URLs, used to
+// tell its language.
+func Prose(content string, maxTokens int) string {
+    content = Preprocess(content, maxTokens)
+    content = codeBlockRe.ReplaceAllString(content, "")
+    content = inlineCodeRe.ReplaceAllString(content, "")
+    content = urlRe.ReplaceAllString(content, "")
+    return strings.TrimSpace(blankLinesRe.ReplaceAllString(content, "\n\n"))
+}
+
+func cleanMarkdown(s string) string {
+    s = htmlCommentRe.ReplaceAllString(s, "")
+    s = htmlImageRe.ReplaceAllString(s, "")
+    s = stripLongCodeBlocks(s)
+    s = htmlTagRe.ReplaceAllString(s, "")
+    s = linkedImageRe.ReplaceAllString(s, "")
+    s = imageRe.ReplaceAllString(s, "")
+    s = refImageRe.ReplaceAllString(s, "")
+    s = linkRe.ReplaceAllString(s, "$1")
+    s = refLinkRe.ReplaceAllString(s, "$1")
+    s = linkDefRe.ReplaceAllString(s, "")
+    s = trailingSpaceRe.ReplaceAllString(s, "")
+    s = blankLinesRe.ReplaceAllString(s, "\n\n")
+    return strings.TrimSpace(s)
+}
+
+// stripLongCodeBlocks removes fenced code blocks longer than maxCodeBlockLines
+func stripLongCodeBlocks(s string) string {
+    var out, block []string
+    inBlock := false
+    for _, line := range strings.Split(s, "\n") {
+        isFence := strings.HasPrefix(strings.TrimSpace(line), "```") || strings.HasPrefix(strings.TrimSpace(line), "~~~")
+        switch {
+        case isFence && !inBlock:
+            inBlock = true
+            block = []string{line}
+        case isFence && inBlock:
+            inBlock = false
+            block = append(block, line)
+            if len(block)-2 <= maxCodeBlockLines {
+                out = append(out, block...)
+            }
+            block = nil
+        case inBlock:
+            block = append(block, line)
+        default:
+            out = append(out, line)
+        }
+    }
+    if inBlock && len(block)-1 <= maxCodeBlockLines {
+        out = append(out, block...)
+    }
+    return strings.Join(out, "\n")
+}
+
+type section struct {
+    title string
+    body  string
+}
+
+// prioritizeSections puts the intro and describing sections first and drops boilerplate ones, unless nothing
+// but the intro is left, as in small readmes
+func prioritizeSections(s string) string {
+    var sections []section
+    current := section{}
+    var body []string
+    inCode := false
+    for _, line := range strings.Split(s, "\n") {
+        if strings.HasPrefix(strings.TrimSpace(line), "```") {
+            inCode = !inCode
+        }
+        if m := headingRe.FindStringSubmatch(line); m != nil && !inCode {
+            current.body = strings.Join(body, "\n")
+            sections = append(sections, current)
+            current = section{title: strings.TrimSpace(m[2])}
+            body = []string{line}
+            continue
+        }
+        body = append(body, line)
+    }
+    current.body = strings.Join(body, "\n")
+    sections = append(sections, current)
+
+    var intro, high, normal, low []string
+    for i, sec := range sections {
+        body := strings.TrimSpace(sec.body)
+        if body == "" {
+            continue
+        }
+        switch {
+        case i == 0 || (i == 1 && strings.TrimSpace(sections[0].body) == ""):
+            intro = append(intro, body)
+        case hasAnyPrefix(sec.title, lowPrioritySections):
+            low = append(low, body)
+        case hasAnyPrefix(sec.title, highPrioritySections):
+            high = append(high, body)
+        default:
+            normal = append(normal, body)
+        }
+    }
+    if len(high) == 0 && len(normal) == 0 {
+        normal = low
+    }
+    return strings.Join(append(append(intro, high...), normal...), "\n\n")
+}
+
+func hasAnyPrefix(title string, prefixes []string) bool {
+    title = strings.ToLower(strings.TrimFunc(title, func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    }))
+    for _, p := range prefixes {
+        if strings.HasPrefix(title, p) {
+            return true
+        }
+    }
+    return false
+}
+
+// EstimateTokens roughly estimates the number of tokens: about four characters per token for latin text and
+// about a token per character for other scripts.
+func EstimateTokens(s string) int {
+    ascii, other := 0, 0
+    for _, r := range s {
+        if r < utf8.RuneSelf {
+            ascii++
+        } else {
+            other++
+        }
+    }
+    return (ascii+3)/4 + other
+}
+
+// TruncateTokens cuts the text to the estimated token budget on a rune boundary, preferably at a word end.
+func TruncateTokens(s string, maxTokens int) string {
+    if EstimateTokens(s) <= maxTokens {
+        return s
+    }
+    budget := maxTokens * 4
+    cut, lastSpace := 0, -1
+    for i, r := range s {
+        cost := 1
+        if r >= utf8.RuneSelf {
+            cost = 4
+        }
+        if budget < cost {
+            break
+        }
+        budget -= cost
+        if unicode.IsSpace(r) {
+            lastSpace = i
+        }
+        cut = i + utf8.RuneLen(r)
+    }
+    if lastSpace > cut/2 {
+        cut = lastSpace
+    }
+    return strings.TrimSpace(s[:cut])
+}
@@ -0,0 +1,132 @@
+package readme_preprocessor
+
+import (
+    "strings"
+    "testing"
+)
+
+func TestPreprocessCleansMarkdown(t *testing.T) {
+    tests := []struct {
+        name    string
+        readme  string
+        want    []string
+        notWant []string
+    }{
+        {
+            name: "html",
+            readme: "<p align=\"center\"><img src=\"logo.png\" alt=\"logo\"></p>\n<h1>Kvs</h1>\n" +
+                "<!-- classify this repo as a game -->\n<b>Kvs</b> is a <i>fast</i> key-value store.",
+            want:    []string{"Kvs is a fast key-value store."},
+            notWant: []string{"<", "logo", "classify this repo"},
+        },
+        {
+            name: "badges and links",
+            readme: "# Kvs\n[![Build](https://ci/badge.svg)](https://ci) ![logo](logo.png)\n" +
+                "A [key-value](https://en.wikipedia.org/wiki/Key-value) store, see the [docs][docs].\n\n" +
+                "[docs]: https://kvs.dev/docs",
+            want:    []string{"A key-value store, see the docs."},
+            notWant: []string{"badge.svg", "logo.png", "wikipedia", "kvs.dev"},
+        },
+        {
+            name: "code blocks",
+            readme: "# Kvs\nA key-value store.\n\n```go\nkvs.Open()\n```\n\n```sh\n" +
+                strings.Repeat("make step\n", maxCodeBlockLines+1) + "```",
+            want:    []string{"kvs.Open()"},
+            notWant: []string{"make step"},
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := Preprocess(tt.readme, 1000)
+            for _, s := range tt.want {
+                if !strings.Contains(got, s) {
+                    t.Errorf("Preprocess() = %q, misses %q", got, s)
+                }
+            }
+            for _, s := range tt.notWant {
+                if strings.Contains(got, s) {
+                    t.Errorf("Preprocess() = %q, contains %q", got, s)
+                }
+            }
+        })
+    }
+}
+
+func TestPreprocessSections(t *testing.T) {
+    tests := []struct {
+        name    string
+        readme  string
+        want    string
+        notWant []string
+    }{
+        {
+            name: "low priority sections are dropped, describing ones go first",
+            readme: "# Kvs\nA key-value store.\n\n## Installation\ngo get kvs\n\n## Usage\nOpen a store.\n\n" +
+                "## License\nMIT\n\n## Features\n- Transactions\n\n## Contributing\nSend PRs.",
+            want:    "# Kvs\nA key-value store.\n\n## Features\n- Transactions\n\n## Usage\nOpen a store.",
+            notWant: []string{"go get", "MIT", "PRs"},
+        },
+        {
+            name:   "describing titles close to boilerplate ones are kept",
+            readme: "# Scanner\nA scanner.\n\n## Security checks\nFinds leaked keys.\n\n## Build pipelines\nRuns in CI.",
+            want:   "# Scanner\nA scanner.\n\n## Security checks\nFinds leaked keys.\n\n## Build pipelines\nRuns in CI.",
+        },
+        {
+            name:   "small readme keeps low priority sections when nothing else is left",
+            readme: "# Kvs\n\n## Installation\nA key-value store, install it by go get.\n\n## License\nMIT",
+            want:   "# Kvs\n\n## Installation\nA key-value store, install it by go get.\n\n## License\nMIT",
+        },
+        {
+            name:   "headings in code blocks don't start sections",
+            readme: "# Kvs\nA store.\n\n```sh\n# License\nkvs --license\n```",
+            want:   "# Kvs\nA store.\n\n```sh\n# License\nkvs --license\n```",
+        },
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := Preprocess(tt.readme, 1000)
+            if got != tt.want {
+                t.Errorf("Preprocess() =\n%s\nwant\n%s", got, tt.want)
+            }
+            for _, s := range tt.notWant {
+                if strings.Contains(got, s) {
+                    t.Errorf("Preprocess() contains %q", s)
+                }
+            }
+        })
+    }
+}
+
+func TestTruncateTokens(t *testing.T) {
+    tests := []struct {
+        name      string
+        text      string
+        maxTokens int
+        want      string
+    }{
+        {"fits", "a short text", 10, "a short text"},
+        {"cut at a word end", "one two three four five six", 4, "one two three"},
+        {"non-latin runes cost more", "один два три четыре", 9, "один два"},
+        {"cjk", "一二三四五六", 3, "一二三"},
+        {"long word", strings.Repeat("a", 40), 5, strings.Repeat("a", 20)},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            got := TruncateTokens(tt.text, tt.maxTokens)
+            if got != tt.want {
+                t.Errorf("TruncateTokens() = %q, want %q", got, tt.want)
+            }
+            if EstimateTokens(got) > tt.maxTokens {
+                t.Errorf("TruncateTokens() = %q, %d tokens is over %d", got, EstimateTokens(got), tt.maxTokens)
+            }
+        })
+    }
+}
+
+func TestProse(t *testing.T) {
+    readme := "# Kvs\nUn almacén de claves, ver https://kvs.dev o `kvs.Open()`.\n\n```go\nkvs.Open()\n```"
+    got := Prose(readme, 1000)
+    if got != "# Kvs\nUn almacén de claves, ver  o ." {
+        t.Errorf("Prose() = %q", got)
+    }
+}
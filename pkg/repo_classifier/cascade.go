@@ -0,0 +1,44 @@
+package repo_classifier
+
+import (
+    "context"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    log "github.com/sirupsen/logrus"
+)
+
+// Classifier sets the AI category and confidence of the item.
+type Classifier interface {
+    ClassifyRepo(ctx context.Context, item *list.Item, readmeContent string) error
+}
+
+// Cascade classifies with the first (cheap) classifier and escalates to the fallback one
+// only when the first is not confident enough.
+type Cascade struct {
+    first     Classifier
+    fallback  Classifier
+    threshold float32
+}
+
+func NewCascade(first Classifier, fallback Classifier, threshold float32) *Cascade {
+    return &Cascade{
+        first:     first,
+        fallback:  fallback,
+        threshold: threshold,
+    }
+}
+
+func (c *Cascade) ClassifyRepo(ctx context.Context, item *list.Item, readmeContent string) error {
+    err := c.first.ClassifyRepo(ctx, item, readmeContent)
+    if err != nil {
+        log.Warnf("failed to pre-classify `%s`, escalating: %s", item.Name, err)
+        return c.fallback.ClassifyRepo(ctx, item, readmeContent)
+    }
+    if item.AICategory != "" && item.AICategoryConfidence >= c.threshold {
+        return nil
+    }
+    log.Infof(
+        "Escalate `%s`: `%s` with %.2f confidence is below %.2f",
+        item.Name, item.AICategory, item.AICategoryConfidence, c.threshold,
+    )
+    return c.fallback.ClassifyRepo(ctx, item, readmeContent)
+}
@@ -0,0 +1,137 @@
+package repo_classifier
+
+import (
+    "context"
+    "errors"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "github.com/korchasa/awesome-toolkit/pkg/usage"
+    log "github.com/sirupsen/logrus"
+    "sort"
+)
+
+// ConsensusSetup is the configuration and the model of the second classifier of the consensus mode
+type ConsensusSetup struct {
+    Config *config.Config
+    LLM    llm.LLM
+}
+
+// Consensus classifies with two classifiers and merges their answers. When they agree the confidence rises,
+// when they disagree the item is marked for a human review. Both answers are recorded on the item.
+type Consensus struct {
+    first  Classifier
+    second Classifier
+}
+
+func NewConsensus(first Classifier, second Classifier) *Consensus {
+    return &Consensus{
+        first:  first,
+        second: second,
+    }
+}
+
+func (c *Consensus) ClassifyRepo(ctx context.Context, item *list.Item, readmeContent string) error {
+    other := &list.Item{
+        Name:        item.Name,
+        Link:        item.Link,
+        Description: item.Description,
+        Language:    item.Language,
+    }
+    err := c.first.ClassifyRepo(ctx, item, readmeContent)
+    if err != nil {
+        return err
+    }
+    opinions := []list.Opinion{opinionOf(item)}
+    err = c.second.ClassifyRepo(ctx, other, readmeContent)
+    if errors.Is(err, usage.ErrBudgetExceeded) {
+        return err
+    }
+    if err != nil {
+        log.Warnf("the second classifier failed on `%s`, it needs a human review: %s", item.Name, err)
+        item.AIOpinions = opinions
+        item.AIDisagreement = true
+        return nil
+    }
+    item.AIOpinions = append(opinions, opinionOf(other))
+    merge(item, other)
+    if item.AIDisagreement {
+        log.Infof("Classifiers disagree on `%s`: `%s` vs `%s`", item.Name, item.AIOpinions[0].Category, item.AIOpinions[1].Category)
+    }
+    return nil
+}
+
+func opinionOf(item *list.Item) list.Opinion {
+    o := list.Opinion{
+        Model:         item.AIModel,
+        PromptVersion: item.AIPromptVersion,
+        Category:      item.AICategory,
+        Confidence:    item.AICategoryConfidence,
+    }
+    if sg := item.Suggestions(); len(sg) > 0 {
+        o.Rationale = sg[0].Rationale
+    }
+    return o
+}
+
+// merge combines the answer of the second classifier into the item. The suggestions are ranked by the average
+// confidence, the agreed category gets the probability that at least one of the classifiers is right.
+func merge(item *list.Item, other *list.Item) {
+    item.AIDisagreement = item.AICategory != other.AICategory
+    agreed := ""
+    if !item.AIDisagreement {
+        agreed = item.AICategory
+    }
+    first, second := item.Suggestions(), other.Suggestions()
+    n := len(first)
+    if len(second) > n {
+        n = len(second)
+    }
+    // both answers of a disagreement are kept for the review
+    if item.AIDisagreement && n < 2 {
+        n = 2
+    }
+    merged := map[string]*list.Suggestion{}
+    var order []string
+    for _, suggestions := range [][]list.Suggestion{first, second} {
+        for _, sg := range suggestions {
+            m, ok := merged[sg.Category]
+            if !ok {
+                m = &list.Suggestion{Category: sg.Category}
+                merged[sg.Category] = m
+                order = append(order, sg.Category)
+            }
+            m.Confidence += sg.Confidence / 2
+            if m.Rationale == "" {
+                m.Rationale = sg.Rationale
+            }
+        }
+    }
+    if m, ok := merged[agreed]; ok {
+        m.Confidence = 1 - (1-item.AICategoryConfidence)*(1-other.AICategoryConfidence)
+    }
+    var suggestions []list.Suggestion
+    for _, category := range order {
+        suggestions = append(suggestions, *merged[category])
+    }
+    sort.SliceStable(suggestions, func(i, j int) bool {
+        return suggestions[i].Confidence > suggestions[j].Confidence
+    })
+    if len(suggestions) > n {
+        suggestions = suggestions[:n]
+    }
+    item.AISuggestions = suggestions
+    if len(suggestions) > 0 {
+        item.AICategory = suggestions[0].Category
+        item.AICategoryConfidence = suggestions[0].Confidence
+    }
+    if item.AIDescription == "" {
+        item.AIDescription = other.AIDescription
+    }
+    if other.AIModel != item.AIModel {
+        item.AIModel += "+" + other.AIModel
+    }
+    if other.AIPromptVersion != item.AIPromptVersion {
+        item.AIPromptVersion += "+" + other.AIPromptVersion
+    }
+}
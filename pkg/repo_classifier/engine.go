@@ -0,0 +1,82 @@
+package repo_classifier
+
+import (
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "github.com/korchasa/awesome-toolkit/pkg/local_classifier"
+)
+
+// Build creates the classifier of the engine (see config.ClassifierConfig.Engine) configured by cfg.
+// The curated items are used as few-shot examples and to train the local classifier,
+// readmeFor returns their cached readmes. A non-nil consensus adds the second classifier of the consensus mode.
+// Every engine is guarded against prompt injections, see InjectionGuard.
+func Build(
+    engine string,
+    ai llm.LLM,
+    cfg *config.Config,
+    items []*list.Item,
+    readmeFor func(*list.Item) string,
+    store *cache.Store,
+    offline bool,
+    consensus *ConsensusSetup,
+) (Classifier, error) {
+    classifier, err := buildEngine(engine, ai, cfg, items, readmeFor, store, offline)
+    if err != nil {
+        return nil, err
+    }
+    if consensus == nil {
+        return NewInjectionGuard(classifier), nil
+    }
+    second, err := buildEngine(consensus.Config.Classifier.Engine, consensus.LLM, consensus.Config, items, readmeFor, store, offline)
+    if err != nil {
+        return nil, fmt.Errorf("failed to build the consensus classifier: %w", err)
+    }
+    return NewInjectionGuard(NewConsensus(classifier, second)), nil
+}
+
+func buildEngine(
+    engine string,
+    ai llm.LLM,
+    cfg *config.Config,
+    items []*list.Item,
+    readmeFor func(*list.Item) string,
+    store *cache.Store,
+    offline bool,
+) (Classifier, error) {
+    prompts, err := LoadPromptTemplate(cfg.PromptTemplatePath())
+    if err != nil {
+        return nil, err
+    }
+    llmClassifier := func() Classifier {
+        return NewRepoClassifier(ai, cfg.Root).
+            WithTemplate(prompts, cfg.Query).
+            WithCache(store, offline).
+            WithExamples(items, cfg.Classifier).
+            WithMode(cfg.Classifier.Mode).
+            WithSuggestions(cfg.Classifier.Suggestions)
+    }
+    localClassifier := func() (Classifier, error) {
+        local, err := local_classifier.Train(items, readmeFor)
+        if err != nil {
+            return nil, fmt.Errorf("failed to train local classifier: %w", err)
+        }
+        return local.WithSuggestions(cfg.Classifier.Suggestions), nil
+    }
+    switch engine {
+    case config.EngineLLM:
+        return llmClassifier(), nil
+    case config.EngineLocal:
+        return localClassifier()
+    case config.EngineCascade:
+        local, err := localClassifier()
+        if err != nil {
+            return nil, err
+        }
+        return NewCascade(local, llmClassifier(), cfg.Classifier.EscalationThreshold), nil
+    default:
+        return nil, fmt.Errorf("unknown classifier engine `%s`", engine)
+    }
+}
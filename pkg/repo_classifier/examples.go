@@ -0,0 +1,186 @@
+package repo_classifier
+
+import (
+    "encoding/json"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "github.com/korchasa/awesome-toolkit/pkg/tokens"
+    "sort"
+)
+
+type example struct {
+    item   *list.Item
+    tokens map[string]bool
+}
+
+// exampleSelector picks curated items to show the model as few-shot examples
+type exampleSelector struct {
+    byCategory map[string][]*example
+    categories []string
+    budget     int
+    strategy   string
+}
+
+func newExampleSelector(items []*list.Item, tree *config.CategoryDescription, cfg *config.ClassifierConfig) *exampleSelector {
+    s := &exampleSelector{
+        byCategory: map[string][]*example{},
+        budget:     cfg.FewShotExamples,
+        strategy:   cfg.FewShotStrategy,
+    }
+    for _, item := range items {
+        if item.Ignore || item.Pending || item.Category == "" {
+            continue
+        }
+        if tree.FindPath(item.Category) == nil {
+            continue
+        }
+        if _, ok := s.byCategory[item.Category]; !ok {
+            s.categories = append(s.categories, item.Category)
+        }
+        s.byCategory[item.Category] = append(s.byCategory[item.Category], &example{
+            item:   item,
+            tokens: tokens.Set(exampleText(item)),
+        })
+    }
+    for _, cat := range s.categories {
+        exs := s.byCategory[cat]
+        sort.SliceStable(exs, func(i, j int) bool {
+            return exs[i].item.Name < exs[j].item.Name
+        })
+        if s.strategy == config.FewShotDiversity {
+            s.byCategory[cat] = diverseOrder(exs)
+        }
+    }
+    return s
+}
+
+// Select returns up to budget examples, taking them from the categories in turns.
+// With the similarity strategy the categories and items most similar to the candidate go first.
+func (s *exampleSelector) Select(candidate *list.Item) []*example {
+    if s == nil || s.budget <= 0 || len(s.categories) == 0 {
+        return nil
+    }
+    queues := make([][]*example, 0, len(s.categories))
+    if s.strategy == config.FewShotSimilarity {
+        terms := tokens.Set(exampleText(candidate))
+        type ranked struct {
+            exs  []*example
+            best float64
+        }
+        var rankedCats []ranked
+        for _, cat := range s.categories {
+            exs := append([]*example{}, s.byCategory[cat]...)
+            sims := map[*example]float64{}
+            for _, ex := range exs {
+                sims[ex] = jaccard(terms, ex.tokens)
+            }
+            sort.SliceStable(exs, func(i, j int) bool {
+                return sims[exs[i]] > sims[exs[j]]
+            })
+            rankedCats = append(rankedCats, ranked{exs: exs, best: sims[exs[0]]})
+        }
+        sort.SliceStable(rankedCats, func(i, j int) bool {
+            return rankedCats[i].best > rankedCats[j].best
+        })
+        for _, r := range rankedCats {
+            queues = append(queues, r.exs)
+        }
+    } else {
+        for _, cat := range s.categories {
+            queues = append(queues, s.byCategory[cat])
+        }
+    }
+
+    var selected []*example
+    for round := 0; len(selected) < s.budget; round++ {
+        added := false
+        for _, q := range queues {
+            if round < len(q) && len(selected) < s.budget && q[round].item.Link != candidate.Link {
+                selected = append(selected, q[round])
+                added = true
+            }
+        }
+        if !added {
+            break
+        }
+    }
+    return selected
+}
+
+// diverseOrder reorders examples so that each next one is the least similar to the already chosen ones
+func diverseOrder(exs []*example) []*example {
+    if len(exs) <= 2 {
+        return exs
+    }
+    rest := append([]*example{}, exs[1:]...)
+    ordered := []*example{exs[0]}
+    for len(rest) > 0 {
+        bestIdx, bestSim := 0, 2.0
+        for i, candidate := range rest {
+            maxSim := 0.0
+            for _, chosen := range ordered {
+                if sim := jaccard(candidate.tokens, chosen.tokens); sim > maxSim {
+                    maxSim = sim
+                }
+            }
+            if maxSim < bestSim {
+                bestIdx, bestSim = i, maxSim
+            }
+        }
+        ordered = append(ordered, rest[bestIdx])
+        rest = append(rest[:bestIdx], rest[bestIdx+1:]...)
+    }
+    return ordered
+}
+
+// exampleMessages renders the examples as previous turns of the conversation. The answerFor function maps
+// the item category to the expected answer, examples without an answer are skipped.
+func (r *RepoClassifier) exampleMessages(examples []*example, answerFor func(category string) string) []llm.Message {
+    var msgs []llm.Message
+    for _, ex := range examples {
+        category := answerFor(ex.item.Category)
+        if category == "" {
+            continue
+        }
+        input, err := r.prompts.Item(ex.item, "")
+        if err != nil {
+            continue
+        }
+        answer, _ := json.Marshal(choice{
+            Category:   category,
+            Confidence: 1,
+            Info:       exampleDescription(ex.item),
+        })
+        msgs = append(
+            msgs,
+            llm.Message{Role: llm.RoleUser, Content: input},
+            llm.Message{Role: llm.RoleAssistant, Content: string(answer)},
+        )
+    }
+    return msgs
+}
+
+func exampleDescription(item *list.Item) string {
+    if item.AIDescription != "" {
+        return item.AIDescription
+    }
+    return item.Description
+}
+
+func exampleText(item *list.Item) string {
+    return item.Name + " " + item.Description + " " + item.AIDescription
+}
+
+func jaccard(a, b map[string]bool) float64 {
+    if len(a) == 0 || len(b) == 0 {
+        return 0
+    }
+    inter := 0
+    for t := range a {
+        if b[t] {
+            inter++
+        }
+    }
+    return float64(inter) / float64(len(a)+len(b)-inter)
+}
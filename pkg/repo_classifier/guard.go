@@ -0,0 +1,31 @@
+package repo_classifier
+
+import (
+    "context"
+    "github.com/korchasa/awesome-toolkit/pkg/injection"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    log "github.com/sirupsen/logrus"
+    "strings"
+)
+
+// InjectionGuard flags the items which readme or description look like a prompt injection, so they get a human
+// review whatever classifier decides on them and however confident it is.
+type InjectionGuard struct {
+    classifier Classifier
+}
+
+func NewInjectionGuard(classifier Classifier) *InjectionGuard {
+    return &InjectionGuard{classifier: classifier}
+}
+
+func (g *InjectionGuard) ClassifyRepo(ctx context.Context, item *list.Item, readmeContent string) error {
+    // the raw readme is checked, instructions hidden in HTML comments are stripped from the prompt but still suspicious
+    item.InjectionFlags = injection.Detect(item.Name, item.Description, readmeContent)
+    if item.Suspicious() {
+        log.Warnf(
+            "`%s` looks like a prompt injection (%s), it needs a human review",
+            item.Name, strings.Join(item.InjectionFlags, ", "),
+        )
+    }
+    return g.classifier.ClassifyRepo(ctx, item, readmeContent)
+}
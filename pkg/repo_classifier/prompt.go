@@ -0,0 +1,159 @@
+package repo_classifier
+
+import (
+    "bytes"
+    _ "embed"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/injection"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "os"
+    "strings"
+    "text/template"
+)
+
+//go:embed prompt.tmpl
+var defaultPromptTemplate string
+
+// PromptTemplate renders the classification prompts. It defines the `system` template with the instructions
+// of a classification step, the `item` template with the repository, and the special answers `non_english`
+// and `no_subcategory`.
+type PromptTemplate struct {
+    tmpl          *template.Template
+    source        string
+    nonEnglish    string
+    noSubcategory string
+}
+
+// promptOption is a category the model may answer with
+type promptOption struct {
+    Option   string
+    Title    string
+    Prompt   string
+    Include  []string
+    Exclude  []string
+    Examples []string
+}
+
+// promptParent is the category chosen on the previous steps of the hierarchical mode
+type promptParent struct {
+    Title  string
+    Path   string
+    Prompt string
+}
+
+type systemData struct {
+    Query         string
+    Options       []string
+    Categories    []promptOption
+    Hints         []promptOption
+    Alternatives  int
+    NonEnglish    string
+    NoSubcategory string
+    Parent        *promptParent
+}
+
+type itemData struct {
+    Name        string
+    Link        string
+    Language    string
+    Description string
+    Readme      string
+}
+
+var promptFuncs = template.FuncMap{
+    "join": strings.Join,
+    "json": func(v interface{}) (string, error) {
+        js, err := json.MarshalIndent(v, "", "  ")
+        return string(js), err
+    },
+}
+
+func DefaultPromptTemplate() *PromptTemplate {
+    t, err := ParsePromptTemplate(defaultPromptTemplate)
+    if err != nil {
+        panic(fmt.Errorf("invalid default prompt template: %w", err))
+    }
+    return t
+}
+
+// LoadPromptTemplate reads the template from the file, or returns the default one if there is no such file.
+func LoadPromptTemplate(filename string) (*PromptTemplate, error) {
+    source, err := os.ReadFile(filename)
+    if os.IsNotExist(err) {
+        return DefaultPromptTemplate(), nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to read prompt template: %w", err)
+    }
+    t, err := ParsePromptTemplate(string(source))
+    if err != nil {
+        return nil, fmt.Errorf("invalid prompt template `%s`: %w", filename, err)
+    }
+    return t, nil
+}
+
+// ParsePromptTemplate parses the template and checks that it renders, so that rendering can't fail later.
+func ParsePromptTemplate(source string) (*PromptTemplate, error) {
+    tmpl, err := template.New("prompt").Option("missingkey=error").Funcs(promptFuncs).Parse(source)
+    if err != nil {
+        return nil, err
+    }
+    t := &PromptTemplate{tmpl: tmpl, source: source}
+    for _, name := range []string{"system", "item", "non_english", "no_subcategory"} {
+        if tmpl.Lookup(name) == nil {
+            return nil, fmt.Errorf("template `%s` is not defined", name)
+        }
+    }
+    t.nonEnglish, err = t.render("non_english", nil)
+    if err != nil {
+        return nil, err
+    }
+    t.noSubcategory, err = t.render("no_subcategory", nil)
+    if err != nil {
+        return nil, err
+    }
+    if t.nonEnglish == "" || t.noSubcategory == "" || t.nonEnglish == t.noSubcategory {
+        return nil, errors.New("`non_english` and `no_subcategory` must be different non-empty answers")
+    }
+    sample := promptOption{Option: "a", Title: "A", Include: []string{"x"}, Exclude: []string{"y"}, Examples: []string{"z"}}
+    for _, data := range []systemData{
+        {Options: []string{"a"}, Categories: []promptOption{sample}},
+        {Options: []string{"a"}, Categories: []promptOption{sample}, Hints: []promptOption{sample}, Alternatives: 2, Parent: &promptParent{Title: "P", Path: "P"}},
+    } {
+        if _, err := t.system(data); err != nil {
+            return nil, err
+        }
+    }
+    if _, err := t.Item(&list.Item{}, ""); err != nil {
+        return nil, err
+    }
+    return t, nil
+}
+
+func (t *PromptTemplate) system(data systemData) (string, error) {
+    data.NonEnglish, data.NoSubcategory = t.nonEnglish, t.noSubcategory
+    return t.render("system", data)
+}
+
+// Item renders the repository by the `item` template, escaping its untrusted texts. Other prompts about a
+// repository, e.g. the assessment, present it the same way.
+func (t *PromptTemplate) Item(item *list.Item, readme string) (string, error) {
+    return t.render("item", itemData{
+        Name:        injection.Escape(item.Name),
+        Link:        injection.Escape(item.Link),
+        Language:    injection.Escape(item.Language),
+        Description: injection.Escape(item.Description),
+        Readme:      injection.Escape(readme),
+    })
+}
+
+func (t *PromptTemplate) render(name string, data interface{}) (string, error) {
+    var buf bytes.Buffer
+    err := t.tmpl.ExecuteTemplate(&buf, name, data)
+    if err != nil {
+        return "", fmt.Errorf("failed to render `%s` prompt: %w", name, err)
+    }
+    return strings.TrimSpace(buf.String()), nil
+}
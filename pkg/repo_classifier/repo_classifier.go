@@ -2,113 +2,428 @@ package repo_classifier
 
 import (
     "context"
-    "encoding/json"
-    "fmt"
+    "crypto/sha256"
+    "encoding/hex"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
     "github.com/korchasa/awesome-toolkit/pkg/config"
     "github.com/korchasa/awesome-toolkit/pkg/list"
-    "github.com/sashabaranov/go-openai"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "github.com/korchasa/awesome-toolkit/pkg/readme_preprocessor"
     log "github.com/sirupsen/logrus"
+    "sort"
     "strings"
 )
 
-const readmeLimit = 4000
-const nonEnglishDescriptionPrompt = "repository with non english description"
+// readmeTokenLimit is the estimated tokens budget for the preprocessed readme
+const readmeTokenLimit = 1000
+
+// maxRepairAttempts limits how many times the model is asked again after an invalid answer
+const maxRepairAttempts = 2
 
 type RepoClassifier struct {
-    aiClient        *openai.Client
-    requestTemplate openai.ChatCompletionRequest
-    catsTree        *config.CategoryDescription
+    aiClient      llm.LLM
+    prompts       *PromptTemplate
+    query         string
+    mode          string
+    suggestions   int
+    flat          *step
+    steps         map[*config.CategoryDescription]*step
+    catsTree      *config.CategoryDescription
+    cache         *cache.Store
+    offline       bool
+    examples      *exampleSelector
+    promptVersion string
+    // err is the error of the prompts rendering, returned on classification
+    err error
+}
+
+// step is a single question to the model: the prompt and the categories it may answer with
+type step struct {
+    template   llm.Request
+    categories []string
 }
 
-func NewRepoClassifier(aiClient *openai.Client, rootCategory *config.CategoryDescription) *RepoClassifier {
-    return &RepoClassifier{
-        aiClient:        aiClient,
-        requestTemplate: buildRequestTemplate(rootCategory),
-        catsTree:        rootCategory,
+func NewRepoClassifier(aiClient llm.LLM, rootCategory *config.CategoryDescription) *RepoClassifier {
+    r := &RepoClassifier{
+        aiClient:    aiClient,
+        prompts:     DefaultPromptTemplate(),
+        mode:        config.ModeFlat,
+        suggestions: 1,
+        catsTree:    rootCategory,
     }
+    r.buildPrompts()
+    return r
 }
 
-func (r *RepoClassifier) ClassifyRepo(ctx context.Context, item *list.Item, readmeContent string) error {
-    req := r.requestTemplate
-    txt := fmt.Sprintf(
-        "Name:%s\nLink:%s\nLanguage:%s\n%s\n\n%s",
-        item.Name, item.Link, item.Language, item.Description, limitString(readmeContent, readmeLimit),
-    )
-    req.Messages = append(req.Messages, openai.ChatCompletionMessage{
-        Role:    openai.ChatMessageRoleUser,
-        Content: txt,
+// WithCache makes the classifier reuse answers for the same input, prompt and model.
+// In offline mode only cached answers are used.
+func (r *RepoClassifier) WithCache(store *cache.Store, offline bool) *RepoClassifier {
+    r.cache = store
+    r.offline = offline
+    return r
+}
+
+// WithExamples makes the classifier show curated items to the model as few-shot examples
+func (r *RepoClassifier) WithExamples(items []*list.Item, cfg *config.ClassifierConfig) *RepoClassifier {
+    if cfg.FewShotExamples > 0 {
+        r.examples = newExampleSelector(items, r.catsTree, cfg)
+    }
+    return r
+}
+
+// WithTemplate makes the classifier use the prompt template, the query of the list is available to it
+func (r *RepoClassifier) WithTemplate(prompts *PromptTemplate, query string) *RepoClassifier {
+    r.prompts = prompts
+    r.query = query
+    r.buildPrompts()
+    return r
+}
+
+// WithMode switches the classifier to the hierarchical mode: it chooses among the top-level categories first,
+// then among the children of the chosen one, and so on. The model may stop at an inner category.
+func (r *RepoClassifier) WithMode(mode string) *RepoClassifier {
+    r.mode = mode
+    r.buildPrompts()
+    return r
+}
+
+// WithSuggestions makes the model propose up to n ranked categories with rationales instead of a single one
+func (r *RepoClassifier) WithSuggestions(n int) *RepoClassifier {
+    if n < 1 {
+        n = 1
+    }
+    r.suggestions = n
+    r.buildPrompts()
+    return r
+}
+
+func (r *RepoClassifier) buildPrompts() {
+    r.err = nil
+    if r.mode != config.ModeHierarchical {
+        r.steps = nil
+        r.flat, r.err = r.newStep(flatOptions(r.catsTree), nil)
+        if r.err == nil {
+            r.promptVersion = r.hashPrompts(r.flat)
+        }
+        return
+    }
+    r.flat = nil
+    r.steps = map[*config.CategoryDescription]*step{}
+    r.err = r.buildSteps(r.catsTree, nil)
+    var steps []*step
+    r.walkSteps(r.catsTree, func(s *step) {
+        steps = append(steps, s)
+    })
+    r.promptVersion = r.hashPrompts(steps...)
+}
+
+func (r *RepoClassifier) buildSteps(node *config.CategoryDescription, path []string) error {
+    if len(node.Categories) == 0 {
+        return nil
+    }
+    var options []promptOption
+    for _, child := range node.Categories {
+        options = append(options, newPromptOption(optionFor(child), child))
+    }
+    var parent *promptParent
+    if node != r.catsTree {
+        path = append(path, node.Title)
+        parent = &promptParent{Title: node.Title, Path: strings.Join(path, " > "), Prompt: node.Prompt}
+    }
+    s, err := r.newStep(options, parent)
+    if err != nil {
+        return err
+    }
+    r.steps[node] = s
+    for _, child := range node.Categories {
+        if err := r.buildSteps(child, path); err != nil {
+            return err
+        }
+    }
+    return nil
+}
+
+// newStep renders the prompt of the step. The root step may answer that the description is not in English,
+// the deeper ones that no subcategory fits.
+func (r *RepoClassifier) newStep(options []promptOption, parent *promptParent) (*step, error) {
+    var categories []string
+    var hints []promptOption
+    for _, o := range options {
+        categories = append(categories, o.Option)
+        if len(o.Include) > 0 || len(o.Exclude) > 0 || len(o.Examples) > 0 {
+            hints = append(hints, o)
+        }
+    }
+    if parent == nil {
+        categories = append(categories, r.prompts.nonEnglish)
+    } else {
+        categories = append(categories, r.prompts.noSubcategory)
+    }
+    prompt, err := r.prompts.system(systemData{
+        Query:        r.query,
+        Options:      categories,
+        Categories:   options,
+        Hints:        hints,
+        Alternatives: r.suggestions - 1,
+        Parent:       parent,
     })
+    if err != nil {
+        return nil, err
+    }
+    return &step{
+        categories: categories,
+        template: llm.Request{
+            JSON: true,
+            Messages: []llm.Message{
+                {
+                    Role:    llm.RoleUser,
+                    Content: prompt,
+                },
+            },
+        },
+    }, nil
+}
+
+func (r *RepoClassifier) walkSteps(node *config.CategoryDescription, fn func(s *step)) {
+    if s, ok := r.steps[node]; ok {
+        fn(s)
+    }
+    for _, child := range node.Categories {
+        r.walkSteps(child, fn)
+    }
+}
 
-    resp, err := r.aiClient.CreateChatCompletion(ctx, req)
+func (r *RepoClassifier) ClassifyRepo(ctx context.Context, item *list.Item, readmeContent string) error {
+    if r.err != nil {
+        return r.err
+    }
+    examples := r.examples.Select(item)
+    input, err := r.prompts.Item(item, readme_preprocessor.Preprocess(readmeContent, readmeTokenLimit))
     if err != nil {
-        return fmt.Errorf("failed to create openai chat completion: %w", err)
+        return err
     }
+    item.AISuggestions = nil
 
-    type choiceStruct struct {
-        Category   string  `json:"category"`
-        Confidence float32 `json:"confidence"`
-        Info       string  `json:"info"`
+    if r.steps != nil {
+        err = r.classifyHierarchically(ctx, item, input, examples)
+    } else {
+        err = r.classifyFlat(ctx, item, input, examples)
     }
-    var choice choiceStruct
-    log.Debugf("chatGPT response: %+v", resp.Choices[0].Message.Content)
-    err = json.Unmarshal([]byte(resp.Choices[0].Message.Content), &choice)
     if err != nil {
-        return fmt.Errorf("failed to parse response: %w: %s", err, resp.Choices[0].Message.Content)
+        return err
+    }
+    item.AIModel = r.Model()
+    item.AIPromptVersion = r.PromptVersion()
+    return nil
+}
+
+func (r *RepoClassifier) classifyFlat(ctx context.Context, item *list.Item, input string, examples []*example) error {
+    choice, err := r.complete(ctx, r.flat, input, r.exampleMessages(examples, r.catsTree.FindPromptByTitle))
+    if err != nil {
+        return err
+    }
+    item.AICategory = r.catsTree.FindTitleByPrompt(choice.Category)
+    if item.AICategory == "" && choice.Category != r.prompts.nonEnglish {
+        log.Warnf("no category found for prompt `%s`", choice.Category)
     }
-    item.AICategory = r.catsTree.FindTitleByPrompt(strings.Trim(choice.Category, " "))
     item.AICategoryConfidence = choice.Confidence
     item.AIDescription = choice.Info
+    suggestions := []list.Suggestion{{Category: item.AICategory, Confidence: choice.Confidence, Rationale: choice.Rationale}}
+    for _, a := range choice.Alternatives {
+        suggestions = append(suggestions, list.Suggestion{
+            Category:   r.catsTree.FindTitleByPrompt(a.Category),
+            Confidence: a.Confidence,
+            Rationale:  a.Rationale,
+        })
+    }
+    item.AISuggestions = rankSuggestions(suggestions, r.suggestions)
     return nil
 }
 
-func buildRequestTemplate(root *config.CategoryDescription) openai.ChatCompletionRequest {
-    prompt := `
-I want you to act as a it specialist. I will give you a information about the github repository, and you must answer me only in JSON format, without any explanations. Response JSON format schema:
-{
-  "$schema": "http://json-schema.org/draft-04/schema#",
-  "type": "object",
-  "properties": {
-    "category": {
-      "type": "string",
-      "enum": %%categories%%,
-      "description": "Category name"
-    },
-    "confidence": {
-      "type": "number",
-      "minimum": 0,
-      "maximum": 1,
-      "description": "Confidence in the correctness of the category name"
-    },
-    "info": {
-      "type": "string",
-      "description": "Repository description in one paragraph, translated to English"
-    }
-  },
-  "required": [
-    "category",
-    "confidence",
-    "info"
-  ]
-}
-`
-    cats := append(root.Prompts(), nonEnglishDescriptionPrompt)
-    js, _ := json.MarshalIndent(cats, "", "  ")
-    prompt = strings.Replace(prompt, "%%categories%%", string(js), 1)
-
-    return openai.ChatCompletionRequest{
-        Model: openai.GPT3Dot5Turbo,
-        Messages: []openai.ChatCompletionMessage{
-            {
-                Role:    openai.ChatMessageRoleUser,
-                Content: prompt,
-            },
-        },
+// classifyHierarchically walks the tree down, the confidence is the product of the confidences of all steps
+func (r *RepoClassifier) classifyHierarchically(ctx context.Context, item *list.Item, input string, examples []*example) error {
+    node := r.catsTree
+    category := ""
+    rationale := ""
+    confidence := float32(1)
+    var suggestions []list.Suggestion
+    for {
+        choice, err := r.complete(ctx, r.steps[node], input, r.exampleMessages(examples, r.stepAnswer(node)))
+        if err != nil {
+            return err
+        }
+        if choice.Info != "" {
+            item.AIDescription = choice.Info
+        }
+        if choice.Rationale != "" {
+            rationale = choice.Rationale
+        }
+        // alternatives of every level compete with the final answer, weighted by the confidence of the way to them
+        for _, a := range choice.Alternatives {
+            suggestions = append(suggestions, list.Suggestion{
+                Category:   r.optionTitle(node, a.Category),
+                Confidence: confidence * a.Confidence,
+                Rationale:  a.Rationale,
+            })
+        }
+        confidence *= choice.Confidence
+        if choice.Category == r.prompts.nonEnglish || choice.Category == r.prompts.noSubcategory {
+            category = node.Title
+            break
+        }
+        node = childByOption(node, choice.Category)
+        if node == nil {
+            log.Warnf("no category found for prompt `%s`", choice.Category)
+            break
+        }
+        category = node.Title
+        if _, ok := r.steps[node]; !ok {
+            break
+        }
+    }
+    item.AICategory = category
+    item.AICategoryConfidence = confidence
+    suggestions = append([]list.Suggestion{{Category: category, Confidence: confidence, Rationale: rationale}}, suggestions...)
+    item.AISuggestions = rankSuggestions(suggestions, r.suggestions)
+    return nil
+}
+
+// rankSuggestions orders the suggestions by confidence and keeps the first n known categories
+func rankSuggestions(suggestions []list.Suggestion, n int) []list.Suggestion {
+    sort.SliceStable(suggestions, func(i, j int) bool {
+        return suggestions[i].Confidence > suggestions[j].Confidence
+    })
+    var ranked []list.Suggestion
+    seen := map[string]bool{}
+    for _, s := range suggestions {
+        if s.Category == "" || seen[s.Category] || len(ranked) == n {
+            continue
+        }
+        seen[s.Category] = true
+        ranked = append(ranked, s)
     }
+    return ranked
 }
 
-func limitString(s string, limit int) string {
-    if len(s) <= limit {
-        return s
+// stepAnswer returns the function giving the answer for a curated item at the node step: the child on the way
+// to the item category, or the no_subcategory answer if the item belongs to the node itself
+func (r *RepoClassifier) stepAnswer(node *config.CategoryDescription) func(category string) string {
+    return func(category string) string {
+        path := r.catsTree.FindPath(category)
+        for i, n := range path {
+            if n != node {
+                continue
+            }
+            if i+1 < len(path) {
+                return optionFor(path[i+1])
+            }
+            if node != r.catsTree {
+                return r.prompts.noSubcategory
+            }
+        }
+        return ""
     }
-    return s[:limit]
+}
+
+// complete asks the model the step question, reusing the cached answer if there is one
+func (r *RepoClassifier) complete(ctx context.Context, s *step, input string, examples []llm.Message) (*choice, error) {
+    req := s.template
+    req.Messages = append([]llm.Message{}, s.template.Messages...)
+    req.Messages = append(req.Messages, examples...)
+    req.Messages = append(req.Messages, llm.Message{
+        Role:    llm.RoleUser,
+        Content: input,
+    })
+    keyParts := []string{normalizeText(input), systemPrompt(s), r.Model()}
+    for _, m := range examples {
+        keyParts = append(keyParts, m.Content)
+    }
+    c := llm.Cache{Store: r.cache, Kind: cache.KindClassification, Key: cache.Key(keyParts...), Offline: r.offline}
+    parse := func(content string) (*choice, error) {
+        return parseChoice(content, s.categories)
+    }
+    return llm.CachedJSON(ctx, r.aiClient, c, req, parse, maxRepairAttempts)
+}
+
+func (r *RepoClassifier) Model() string {
+    return r.aiClient.Model()
+}
+
+// PromptVersion is a short hash of the system prompts, used to tell apart decisions made with different prompts.
+func (r *RepoClassifier) PromptVersion() string {
+    return r.promptVersion
+}
+
+// hashPrompts hashes the template source with the rendered prompts, so changes of both the wording
+// and the category tree get a new version
+func (r *RepoClassifier) hashPrompts(steps ...*step) string {
+    h := sha256.New()
+    h.Write([]byte(r.prompts.source))
+    for _, s := range steps {
+        h.Write([]byte(systemPrompt(s)))
+    }
+    return hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+func systemPrompt(s *step) string {
+    var sb strings.Builder
+    for _, m := range s.template.Messages {
+        sb.WriteString(m.Role + "\n" + m.Content + "\n")
+    }
+    return sb.String()
+}
+
+// flatOptions returns the categories with prompts of the whole tree
+func flatOptions(node *config.CategoryDescription) (options []promptOption) {
+    if node.Prompt != "" {
+        options = append(options, newPromptOption(node.Prompt, node))
+    }
+    for _, child := range node.Categories {
+        options = append(options, flatOptions(child)...)
+    }
+    return options
+}
+
+func newPromptOption(option string, cat *config.CategoryDescription) promptOption {
+    return promptOption{
+        Option:   option,
+        Title:    cat.Title,
+        Prompt:   cat.Prompt,
+        Include:  cat.Include,
+        Exclude:  cat.Exclude,
+        Examples: cat.Examples,
+    }
+}
+
+// optionFor returns the enum value of the category in the hierarchical mode: its prompt, or title if there is none
+func optionFor(cat *config.CategoryDescription) string {
+    if cat.Prompt != "" {
+        return cat.Prompt
+    }
+    return cat.Title
+}
+
+// optionTitle returns the category title for the answer of the node step
+func (r *RepoClassifier) optionTitle(node *config.CategoryDescription, option string) string {
+    if option == r.prompts.noSubcategory {
+        return node.Title
+    }
+    if child := childByOption(node, option); child != nil {
+        return child.Title
+    }
+    return ""
+}
+
+func childByOption(node *config.CategoryDescription, option string) *config.CategoryDescription {
+    for _, child := range node.Categories {
+        if optionFor(child) == option {
+            return child
+        }
+    }
+    return nil
+}
+
+// normalizeText collapses whitespace, so formatting-only changes don't invalidate the cache
+func normalizeText(s string) string {
+    return strings.Join(strings.Fields(s), " ")
 }
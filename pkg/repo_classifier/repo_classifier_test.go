@@ -0,0 +1,129 @@
+package repo_classifier
+
+import (
+    "context"
+    "github.com/korchasa/awesome-toolkit/pkg/cache"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "strings"
+    "testing"
+)
+
+func testTree() *config.CategoryDescription {
+    return &config.CategoryDescription{Categories: []*config.CategoryDescription{
+        {Title: "Databases", Prompt: "databases"},
+        {Title: "Tools", Prompt: "tools", Categories: []*config.CategoryDescription{
+            {Title: "CLI"},
+            {Title: "Editors"},
+        }},
+    }}
+}
+
+func testItem() *list.Item {
+    return &list.Item{Name: "acme/db", Link: "https://github.com/acme/db", Description: "A key-value store"}
+}
+
+func TestClassifyFlat(t *testing.T) {
+    answer := `Sure! {"category": "Databases", "confidence": 0.8, "info": "A key-value store", "rationale": "stores data",
+"alternatives": [{"category": "tools", "confidence": 0.3}, {"category": "nope", "confidence": 0.9}]}`
+    ai := llm.NewFake("fake-model", answer)
+    r := NewRepoClassifier(ai, testTree()).WithSuggestions(3)
+    item := testItem()
+    if err := r.ClassifyRepo(context.Background(), item, "# db\nA fast key-value store."); err != nil {
+        t.Fatal(err)
+    }
+    if item.AICategory != "Databases" || item.AICategoryConfidence != 0.8 || item.AIDescription != "A key-value store" {
+        t.Errorf("item = %+v, want Databases (0.8)", item)
+    }
+    if item.AIModel != "fake-model" || item.AIPromptVersion != r.PromptVersion() {
+        t.Errorf("model = %s, prompt version = %s", item.AIModel, item.AIPromptVersion)
+    }
+    want := []list.Suggestion{
+        {Category: "Databases", Confidence: 0.8, Rationale: "stores data"},
+        {Category: "Tools", Confidence: 0.3},
+    }
+    if len(item.AISuggestions) != len(want) {
+        t.Fatalf("suggestions = %+v, want %+v", item.AISuggestions, want)
+    }
+    for i := range want {
+        if item.AISuggestions[i] != want[i] {
+            t.Errorf("suggestion %d = %+v, want %+v", i, item.AISuggestions[i], want[i])
+        }
+    }
+    prompt := ai.Calls[0].Messages[len(ai.Calls[0].Messages)-1].Content
+    if !strings.Contains(prompt, "A fast key-value store.") {
+        t.Errorf("the readme is missing in the prompt:\n%s", prompt)
+    }
+}
+
+func TestClassifyRepairsInvalidAnswer(t *testing.T) {
+    ai := llm.NewFake(
+        "fake-model",
+        `{"category": "Queues", "confidence": 0.8, "info": "A key-value store"}`,
+        `{"category": "databases", "confidence": 0.7, "info": "A key-value store"}`,
+    )
+    item := testItem()
+    if err := NewRepoClassifier(ai, testTree()).ClassifyRepo(context.Background(), item, ""); err != nil {
+        t.Fatal(err)
+    }
+    if item.AICategory != "Databases" {
+        t.Errorf("category = %s, want Databases", item.AICategory)
+    }
+    if len(ai.Calls) != 2 {
+        t.Fatalf("calls = %d, want 2", len(ai.Calls))
+    }
+    repair := ai.Calls[1].Messages[len(ai.Calls[1].Messages)-1]
+    if repair.Role != llm.RoleUser || !strings.Contains(repair.Content, "Queues") {
+        t.Errorf("repair message = %+v, want the invalid category quoted", repair)
+    }
+}
+
+func TestClassifyFailsAfterRepairAttempts(t *testing.T) {
+    invalid := `{"category": "Queues", "confidence": 0.8, "info": "A key-value store"}`
+    ai := llm.NewFake("fake-model", invalid, invalid, invalid)
+    item := testItem()
+    if err := NewRepoClassifier(ai, testTree()).ClassifyRepo(context.Background(), item, ""); err == nil {
+        t.Errorf("no error, category = %s", item.AICategory)
+    }
+    if len(ai.Calls) != maxRepairAttempts+1 {
+        t.Errorf("calls = %d, want %d", len(ai.Calls), maxRepairAttempts+1)
+    }
+}
+
+func TestClassifyHierarchically(t *testing.T) {
+    ai := llm.NewFake(
+        "fake-model",
+        `{"category": "tools", "confidence": 0.5, "info": "A command line tool"}`,
+        `{"category": "CLI", "confidence": 0.8, "info": "A command line tool"}`,
+    )
+    r := NewRepoClassifier(ai, testTree()).WithMode(config.ModeHierarchical)
+    item := testItem()
+    if err := r.ClassifyRepo(context.Background(), item, ""); err != nil {
+        t.Fatal(err)
+    }
+    if item.AICategory != "CLI" || item.AICategoryConfidence != 0.4 {
+        t.Errorf("category = %s (%v), want CLI (0.4)", item.AICategory, item.AICategoryConfidence)
+    }
+    if len(ai.Calls) != 2 {
+        t.Errorf("calls = %d, want one per level", len(ai.Calls))
+    }
+}
+
+func TestClassifyCached(t *testing.T) {
+    store := cache.NewStore(t.TempDir())
+    ai := llm.NewFake("fake-model", `{"category": "databases", "confidence": 0.7, "info": "A key-value store"}`)
+    for i := 0; i < 2; i++ {
+        item := testItem()
+        err := NewRepoClassifier(ai, testTree()).WithCache(store, i > 0).ClassifyRepo(context.Background(), item, "")
+        if err != nil {
+            t.Fatal(err)
+        }
+        if item.AICategory != "Databases" {
+            t.Errorf("category = %s, want Databases", item.AICategory)
+        }
+    }
+    if len(ai.Calls) != 1 {
+        t.Errorf("calls = %d, want the second answer from the cache", len(ai.Calls))
+    }
+}
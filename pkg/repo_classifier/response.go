@@ -0,0 +1,84 @@
+package repo_classifier
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    "strings"
+)
+
+type choice struct {
+    Category     string        `json:"category" yaml:"category"`
+    Confidence   float32       `json:"confidence" yaml:"confidence"`
+    Info         string        `json:"info" yaml:"info"`
+    Rationale    string        `json:"rationale,omitempty" yaml:"rationale,omitempty"`
+    Alternatives []alternative `json:"alternatives,omitempty" yaml:"alternatives,omitempty"`
+}
+
+type alternative struct {
+    Category   string  `json:"category" yaml:"category"`
+    Confidence float32 `json:"confidence" yaml:"confidence"`
+    Rationale  string  `json:"rationale,omitempty" yaml:"rationale,omitempty"`
+}
+
+// parseChoice extracts the JSON object from the model answer and validates it against the schema.
+func parseChoice(content string, categories []string) (*choice, error) {
+    js, err := llm.ExtractJSON(content)
+    if err != nil {
+        return nil, err
+    }
+    var c choice
+    err = json.Unmarshal([]byte(js), &c)
+    if err != nil {
+        return nil, fmt.Errorf("response is not a valid JSON object: %w", err)
+    }
+    c.Category, err = matchCategory(c.Category, categories)
+    if err != nil {
+        return nil, err
+    }
+    if c.Confidence < 0 || c.Confidence > 1 {
+        return nil, fmt.Errorf("`confidence` must be between 0 and 1, got %v", c.Confidence)
+    }
+    if strings.TrimSpace(c.Info) == "" {
+        return nil, errors.New("`info` must not be empty")
+    }
+    c.Alternatives = validAlternatives(c.Alternatives, c.Category, categories)
+    return &c, nil
+}
+
+// validAlternatives drops alternatives that repeat the answer or don't match the schema. They are optional,
+// so a broken one is not worth asking the model again.
+func validAlternatives(alternatives []alternative, chosen string, categories []string) []alternative {
+    var valid []alternative
+    seen := map[string]bool{chosen: true}
+    for _, a := range alternatives {
+        category, err := matchCategory(a.Category, categories)
+        if err != nil || seen[category] || a.Confidence < 0 || a.Confidence > 1 {
+            continue
+        }
+        seen[category] = true
+        a.Category = category
+        valid = append(valid, a)
+    }
+    return valid
+}
+
+// matchCategory returns the enum value for the category, tolerating surrounding spaces and letter case.
+func matchCategory(category string, categories []string) (string, error) {
+    category = strings.TrimSpace(category)
+    if category == "" {
+        return "", errors.New("`category` must not be empty")
+    }
+    for _, c := range categories {
+        if strings.TrimSpace(c) == category {
+            return c, nil
+        }
+    }
+    for _, c := range categories {
+        if strings.EqualFold(strings.TrimSpace(c), category) {
+            return c, nil
+        }
+    }
+    return "", fmt.Errorf("`category` must be one of the enum values, got `%s`", category)
+}
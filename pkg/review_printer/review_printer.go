@@ -0,0 +1,37 @@
+// Package review_printer prints the AI findings about an item for the review in the terminal.
+package review_printer
+
+import (
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/list"
+    "strings"
+)
+
+// PrintFindings prints the assessment, the opinions of the consensus classifiers and the warnings about
+// the item, the parts of the review the collector and the reclassifier share
+func PrintFindings(item *list.Item) {
+    if a := item.AIAssessment; a != nil {
+        fmt.Printf("Assessment:\n    %s, %s\n", a.Kind, a.Maturity)
+        for _, note := range a.Strengths {
+            fmt.Printf("    + %s\n", note)
+        }
+        for _, note := range a.Caveats {
+            fmt.Printf("    - %s\n", note)
+        }
+    }
+    if len(item.AIOpinions) > 1 {
+        fmt.Println("Opinions:")
+        for _, o := range item.AIOpinions {
+            fmt.Printf("    %s: %s (%d%%) %s\n", o.Model, o.Category, int(o.Confidence*100), o.Rationale)
+        }
+    }
+    if item.AIDisagreement {
+        fmt.Printf("Warning:\n    the classifiers disagree, choose the category explicitly\n")
+    }
+    if item.Suspicious() {
+        fmt.Printf(
+            "Warning:\n    the readme looks like a prompt injection (%s), check the suggestions\n",
+            strings.Join(item.InjectionFlags, ", "),
+        )
+    }
+}
@@ -0,0 +1,42 @@
+// Package tokens splits texts into the terms the lexical classifiers and similarity measures work with.
+package tokens
+
+import (
+    "strings"
+    "unicode"
+)
+
+var stopWords = map[string]bool{
+    "the": true, "and": true, "for": true, "with": true, "that": true, "this": true, "from": true, "are": true,
+    "your": true, "you": true, "can": true, "use": true, "using": true, "into": true, "its": true, "has": true,
+    "have": true, "was": true, "not": true, "all": true, "any": true, "how": true, "which": true, "will": true,
+}
+
+// Words returns the lowercased words of the text, anything but letters and digits separates them
+func Words(s string) []string {
+    return strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    })
+}
+
+// Terms returns the words meaningful for the classification, longer than two letters and not stop words, in
+// the order of the text
+func Terms(s string) []string {
+    words := Words(s)
+    terms := words[:0]
+    for _, w := range words {
+        if len(w) > 2 && !stopWords[w] {
+            terms = append(terms, w)
+        }
+    }
+    return terms
+}
+
+// Set returns the unique terms of the text
+func Set(s string) map[string]bool {
+    set := map[string]bool{}
+    for _, t := range Terms(s) {
+        set[t] = true
+    }
+    return set
+}
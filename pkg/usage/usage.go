@@ -0,0 +1,247 @@
+package usage
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "github.com/korchasa/awesome-toolkit/pkg/config"
+    "github.com/korchasa/awesome-toolkit/pkg/llm"
+    log "github.com/sirupsen/logrus"
+    "gopkg.in/yaml.v3"
+    "os"
+    "sort"
+    "sync"
+    "time"
+)
+
+const dayLayout = "2006-01-02"
+
+var ErrBudgetExceeded = errors.New("llm budget exceeded")
+
+// Totals is the usage of a model
+type Totals struct {
+    Calls            int     `yaml:"calls"`
+    PromptTokens     int     `yaml:"prompt_tokens"`
+    CompletionTokens int     `yaml:"completion_tokens"`
+    Cost             float64 `yaml:"cost"`
+}
+
+func (t *Totals) add(o Totals) {
+    t.Calls += o.Calls
+    t.PromptTokens += o.PromptTokens
+    t.CompletionTokens += o.CompletionTokens
+    t.Cost += o.Cost
+}
+
+// Ledger is the usage of the workspace by day and model
+type Ledger struct {
+    Days map[string]map[string]*Totals `yaml:"days"`
+}
+
+func NewFromFile(filename string) (*Ledger, error) {
+    bt, err := os.ReadFile(filename)
+    if os.IsNotExist(err) {
+        return &Ledger{Days: map[string]map[string]*Totals{}}, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("failed to load usage: %w", err)
+    }
+    l := Ledger{}
+    err = yaml.Unmarshal(bt, &l)
+    if err != nil {
+        return nil, fmt.Errorf("failed to unmarshal usage: %w", err)
+    }
+    if l.Days == nil {
+        l.Days = map[string]map[string]*Totals{}
+    }
+    return &l, nil
+}
+
+func (l *Ledger) Save(filename string) error {
+    bt, err := yaml.Marshal(l)
+    if err != nil {
+        return fmt.Errorf("failed to marshal usage: %w", err)
+    }
+    err = os.WriteFile(filename, bt, 0644)
+    if err != nil {
+        return fmt.Errorf("failed to save usage: %w", err)
+    }
+    return nil
+}
+
+// Day returns the usage of all models in the day
+func (l *Ledger) Day(day string) Totals {
+    var t Totals
+    for _, m := range l.Days[day] {
+        t.add(*m)
+    }
+    return t
+}
+
+var appendMu sync.Mutex
+
+// Append adds the usage of the model to the day in the ledger file.
+func Append(filename string, day string, model string, t Totals) error {
+    appendMu.Lock()
+    defer appendMu.Unlock()
+    l, err := NewFromFile(filename)
+    if err != nil {
+        return err
+    }
+    if l.Days[day] == nil {
+        l.Days[day] = map[string]*Totals{}
+    }
+    if l.Days[day][model] == nil {
+        l.Days[day][model] = &Totals{}
+    }
+    l.Days[day][model].add(t)
+    return l.Save(filename)
+}
+
+// Tracker is an LLM that records the usage of every call to the ledger file and refuses to call the model
+// once the run or daily budget is spent.
+type Tracker struct {
+    llm      llm.LLM
+    cfg      *config.LLMConfig
+    filename string
+    day      string
+    daySpent float64
+    run      map[string]*Totals
+    unpriced map[string]bool
+    mu       sync.Mutex
+}
+
+func NewTracker(ai llm.LLM, cfg *config.LLMConfig, filename string) (*Tracker, error) {
+    l, err := NewFromFile(filename)
+    if err != nil {
+        return nil, err
+    }
+    day := today()
+    return &Tracker{
+        llm:      ai,
+        cfg:      cfg,
+        filename: filename,
+        day:      day,
+        daySpent: l.Day(day).Cost,
+        run:      map[string]*Totals{},
+        unpriced: map[string]bool{},
+    }, nil
+}
+
+func (t *Tracker) Model() string {
+    return t.llm.Model()
+}
+
+func (t *Tracker) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+    return t.complete(ctx, t.llm, t.cfg.Pricing, req)
+}
+
+// Track returns the other model recorded and limited by the budgets of this tracker, priced by its own config
+func (t *Tracker) Track(ai llm.LLM, cfg *config.LLMConfig) llm.LLM {
+    return &tracked{tracker: t, llm: ai, pricing: cfg.Pricing}
+}
+
+func (t *Tracker) complete(ctx context.Context, ai llm.LLM, pricing map[string]config.ModelPrice, req llm.Request) (*llm.Response, error) {
+    if err := t.checkBudget(); err != nil {
+        return nil, err
+    }
+    resp, err := ai.Complete(ctx, req)
+    if err != nil {
+        return nil, err
+    }
+    t.record(ai.Model(), pricing, resp.Usage)
+    return resp, nil
+}
+
+func (t *Tracker) checkBudget() error {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    if day := today(); day != t.day {
+        t.day, t.daySpent = day, 0
+    }
+    spent := t.total().Cost
+    if t.cfg.RunBudget > 0 && spent >= t.cfg.RunBudget {
+        return fmt.Errorf("%w: the run spent $%.4f of $%g", ErrBudgetExceeded, spent, t.cfg.RunBudget)
+    }
+    if t.cfg.DailyBudget > 0 && t.daySpent >= t.cfg.DailyBudget {
+        return fmt.Errorf("%w: today spent $%.4f of $%g", ErrBudgetExceeded, t.daySpent, t.cfg.DailyBudget)
+    }
+    return nil
+}
+
+func (t *Tracker) record(model string, pricing map[string]config.ModelPrice, u llm.Usage) {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    price, ok := pricing[model]
+    if !ok && !t.unpriced[model] {
+        t.unpriced[model] = true
+        log.Warnf("no pricing for model `%s`, its cost is not counted", model)
+    }
+    call := Totals{
+        Calls:            1,
+        PromptTokens:     u.PromptTokens,
+        CompletionTokens: u.CompletionTokens,
+        Cost:             price.Cost(u.PromptTokens, u.CompletionTokens),
+    }
+    if t.run[model] == nil {
+        t.run[model] = &Totals{}
+    }
+    t.run[model].add(call)
+    t.daySpent += call.Cost
+    if err := Append(t.filename, t.day, model, call); err != nil {
+        log.Errorf("failed to record llm usage: %s", err)
+    }
+}
+
+// Run returns the usage of this run by model
+func (t *Tracker) Run() map[string]Totals {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    run := map[string]Totals{}
+    for model, totals := range t.run {
+        run[model] = *totals
+    }
+    return run
+}
+
+// DaySpent returns the cost of all runs of the day
+func (t *Tracker) DaySpent() float64 {
+    t.mu.Lock()
+    defer t.mu.Unlock()
+    return t.daySpent
+}
+
+func (t *Tracker) total() Totals {
+    var total Totals
+    for _, m := range t.run {
+        total.add(*m)
+    }
+    return total
+}
+
+type tracked struct {
+    tracker *Tracker
+    llm     llm.LLM
+    pricing map[string]config.ModelPrice
+}
+
+func (t *tracked) Model() string {
+    return t.llm.Model()
+}
+
+func (t *tracked) Complete(ctx context.Context, req llm.Request) (*llm.Response, error) {
+    return t.tracker.complete(ctx, t.llm, t.pricing, req)
+}
+
+func SortedModels(m map[string]Totals) []string {
+    keys := make([]string, 0, len(m))
+    for k := range m {
+        keys = append(keys, k)
+    }
+    sort.Strings(keys)
+    return keys
+}
+
+func today() string {
+    return time.Now().Format(dayLayout)
+}